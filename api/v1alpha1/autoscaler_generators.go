@@ -1,46 +1,76 @@
 package v1alpha1
 
 import (
+	"maps"
+
 	hpav2 "k8s.io/api/autoscaling/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 )
 
+// HPAName returns the name of the HPA generated for the CranePodAutoscaler.
+func (r *CranePodAutoscaler) HPAName() string {
+	if r.Spec.HPATemplate == nil {
+		return r.Name
+	}
+	return r.Name + r.Spec.HPATemplate.Metadata.NameSuffix
+}
+
+// VPAName returns the name of the VPA generated for the CranePodAutoscaler.
+func (r *CranePodAutoscaler) VPAName() string {
+	if r.Spec.VPATemplate == nil {
+		return r.Name
+	}
+	return r.Name + r.Spec.VPATemplate.Metadata.NameSuffix
+}
+
+// templateObjectMeta returns the metadata of a generated object with the labels and annotations of its template.
+func (r *CranePodAutoscaler) templateObjectMeta(name string, template *AutoscalerTemplate) metav1.ObjectMeta {
+	objectMeta := metav1.ObjectMeta{
+		Name:      name,
+		Namespace: r.Namespace,
+	}
+	if template != nil {
+		objectMeta.Labels = maps.Clone(template.Metadata.Labels)
+		objectMeta.Annotations = maps.Clone(template.Metadata.Annotations)
+	}
+	return objectMeta
+}
+
 func (r *CranePodAutoscaler) GenerateEnabledVPA() *vpav1.VerticalPodAutoscaler {
 	vpaSpec := r.Spec.VPA.DeepCopy()
 	return &vpav1.VerticalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      r.Name,
-			Namespace: r.Namespace,
-		},
-		Spec: *vpaSpec,
+		ObjectMeta: r.templateObjectMeta(r.VPAName(), r.Spec.VPATemplate),
+		Spec:       *vpaSpec,
 	}
 }
 
+// GenerateSplitVPA returns the VPA for Split mode, which only controls the resources the HPA metrics do not use.
+func (r *CranePodAutoscaler) GenerateSplitVPA() *vpav1.VerticalPodAutoscaler {
+	vpa := r.GenerateEnabledVPA()
+	r.restrictToSplitResources(&vpa.Spec)
+	return vpa
+}
+
 func (r *CranePodAutoscaler) GenerateDisabledVPA() *vpav1.VerticalPodAutoscaler {
 	vpaSpec := r.Spec.VPA.DeepCopy()
-	updateModeOff := vpav1.UpdateModeOff
+	updateMode := r.EffectivePassiveVPAUpdateMode()
 	if vpaSpec.UpdatePolicy == nil {
 		vpaSpec.UpdatePolicy = &vpav1.PodUpdatePolicy{}
 	}
-	vpaSpec.UpdatePolicy.UpdateMode = &updateModeOff
+	vpaSpec.UpdatePolicy.UpdateMode = &updateMode
+	r.applyHPABaseline(vpaSpec)
 	return &vpav1.VerticalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      r.Name,
-			Namespace: r.Namespace,
-		},
-		Spec: *vpaSpec,
+		ObjectMeta: r.templateObjectMeta(r.VPAName(), r.Spec.VPATemplate),
+		Spec:       *vpaSpec,
 	}
 }
 
 func (r *CranePodAutoscaler) GenerateEnabledHPA() *hpav2.HorizontalPodAutoscaler {
 	hpaSpec := r.Spec.HPA.DeepCopy()
 	return &hpav2.HorizontalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      r.Name,
-			Namespace: r.Namespace,
-		},
-		Spec: *hpaSpec,
+		ObjectMeta: r.templateObjectMeta(r.HPAName(), r.Spec.HPATemplate),
+		Spec:       *hpaSpec,
 	}
 }
 
@@ -57,10 +87,7 @@ func (r *CranePodAutoscaler) GenerateDisabledHPA() *hpav2.HorizontalPodAutoscale
 
 	hpaSpec.MaxReplicas = minReplicas
 	return &hpav2.HorizontalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      r.Name,
-			Namespace: r.Namespace,
-		},
-		Spec: *hpaSpec,
+		ObjectMeta: r.templateObjectMeta(r.HPAName(), r.Spec.HPATemplate),
+		Spec:       *hpaSpec,
 	}
 }
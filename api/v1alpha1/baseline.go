@@ -0,0 +1,76 @@
+package v1alpha1
+
+import (
+	"slices"
+
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+)
+
+// HPABaselineFrom returns the baseline requests configured in spec.behavior.hpaBaseline. The sources Target and
+// UpperBound take them from the given VPA recommendation. It returns nil if no baseline is configured or
+// the VPA has no recommendation yet.
+func (r *CranePodAutoscaler) HPABaselineFrom(recommendation *vpav1.RecommendedPodResources) []ContainerRequests {
+	baseline := r.Spec.Behavior.HPABaseline
+	if baseline == nil {
+		return nil
+	}
+	if baseline.Source == HPABaselineSourceExplicit {
+		containers := make([]ContainerRequests, len(baseline.Containers))
+		for i := range baseline.Containers {
+			baseline.Containers[i].DeepCopyInto(&containers[i])
+		}
+		return containers
+	}
+	if recommendation == nil {
+		return nil
+	}
+	var containers []ContainerRequests
+	for _, containerRecommendation := range recommendation.ContainerRecommendations {
+		requests := containerRecommendation.Target
+		if baseline.Source == HPABaselineSourceUpperBound {
+			requests = containerRecommendation.UpperBound
+		}
+		if len(requests) == 0 {
+			continue
+		}
+		containers = append(containers, ContainerRequests{ContainerName: containerRecommendation.ContainerName, Requests: requests.DeepCopy()})
+	}
+	return containers
+}
+
+// applyHPABaseline pins the requests the VPA recommends to the HPA baseline recorded in the status,
+// so that pods created while the HPA is active start with the baseline.
+// Containers without a policy of their own inherit the settings of the policy for all containers.
+func (r *CranePodAutoscaler) applyHPABaseline(vpaSpec *vpav1.VerticalPodAutoscalerSpec) {
+	if r.Status.HPABaseline == nil {
+		return
+	}
+	if vpaSpec.ResourcePolicy == nil {
+		vpaSpec.ResourcePolicy = &vpav1.PodResourcePolicy{}
+	}
+	policies := vpaSpec.ResourcePolicy.ContainerPolicies
+	defaultIndex := slices.IndexFunc(policies, func(policy vpav1.ContainerResourcePolicy) bool {
+		return policy.ContainerName == vpav1.DefaultContainerResourcePolicy
+	})
+	for _, container := range r.Status.HPABaseline.Containers {
+		index := slices.IndexFunc(policies, func(policy vpav1.ContainerResourcePolicy) bool {
+			return policy.ContainerName == container.ContainerName
+		})
+		if index < 0 {
+			policy := vpav1.ContainerResourcePolicy{}
+			if defaultIndex >= 0 {
+				policies[defaultIndex].DeepCopyInto(&policy)
+			}
+			policy.ContainerName = container.ContainerName
+			policies = append(policies, policy)
+			index = len(policies) - 1
+		}
+		policy := &policies[index]
+		if policy.Mode != nil && *policy.Mode == vpav1.ContainerScalingModeOff {
+			continue
+		}
+		policy.MinAllowed = container.Requests.DeepCopy()
+		policy.MaxAllowed = container.Requests.DeepCopy()
+	}
+	vpaSpec.ResourcePolicy.ContainerPolicies = policies
+}
@@ -0,0 +1,187 @@
+package v1alpha1
+
+import (
+	"path"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+)
+
+// EffectiveVPAToHPAThresholdPercent returns the threshold for switching from VPA to HPA,
+// falling back to VPACapacityThresholdPercent if it is not set explicitly.
+func (b *CranePodAutoscalerBehavior) EffectiveVPAToHPAThresholdPercent() int32 {
+	if b.VPAToHPAThresholdPercent != 0 {
+		return b.VPAToHPAThresholdPercent
+	}
+	return b.VPACapacityThresholdPercent
+}
+
+// EffectiveHPAToVPAThresholdPercent returns the threshold for switching from HPA to VPA.
+// If it is not set explicitly it falls back to VPACapacityThresholdPercent, but never exceeds
+// the effective VPA to HPA threshold.
+func (b *CranePodAutoscalerBehavior) EffectiveHPAToVPAThresholdPercent() int32 {
+	if b.HPAToVPAThresholdPercent != 0 {
+		return b.HPAToVPAThresholdPercent
+	}
+	return min(b.VPACapacityThresholdPercent, b.EffectiveVPAToHPAThresholdPercent())
+}
+
+// ThresholdPercentsFor returns the VPA to HPA and HPA to VPA thresholds for a resource of a container.
+// A resource threshold for the container takes precedence over one for all containers,
+// which in turn takes precedence over the global thresholds.
+func (b *CranePodAutoscalerBehavior) ThresholdPercentsFor(containerName string, resource corev1.ResourceName) (vpaToHPA int32, hpaToVPA int32) {
+	vpaToHPA = b.EffectiveVPAToHPAThresholdPercent()
+	hpaToVPA = b.EffectiveHPAToVPAThresholdPercent()
+
+	var match *ResourceThreshold
+	for i := range b.ResourceThresholds {
+		t := &b.ResourceThresholds[i]
+		if t.Resource != resource {
+			continue
+		}
+		if t.ContainerName == containerName {
+			match = t
+			break
+		}
+		if t.ContainerName == "" && match == nil {
+			match = t
+		}
+	}
+	if match == nil {
+		return vpaToHPA, hpaToVPA
+	}
+
+	if match.VPAToHPAThresholdPercent != 0 {
+		vpaToHPA = match.VPAToHPAThresholdPercent
+	}
+	if match.HPAToVPAThresholdPercent != 0 {
+		hpaToVPA = match.HPAToVPAThresholdPercent
+	} else {
+		hpaToVPA = min(hpaToVPA, vpaToHPA)
+	}
+	return vpaToHPA, hpaToVPA
+}
+
+// Selects reports whether the container with the given name is considered for the switching decision.
+func (s *ContainerSelection) Selects(containerName string) bool {
+	if s == nil {
+		return true
+	}
+	if s.Aggregation == ContainerAggregationMainContainer {
+		return containerName == s.MainContainer
+	}
+	for _, pattern := range s.Exclude {
+		if matched, _ := path.Match(pattern, containerName); matched {
+			return false
+		}
+	}
+	if len(s.Include) == 0 {
+		return true
+	}
+	for _, pattern := range s.Include {
+		if matched, _ := path.Match(pattern, containerName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// AggregationOrDefault returns the configured aggregation or Max if none is set.
+func (s *ContainerSelection) AggregationOrDefault() ContainerAggregation {
+	if s == nil || s.Aggregation == "" {
+		return ContainerAggregationMax
+	}
+	return s.Aggregation
+}
+
+// WeightOf returns the weight of the container with the given name in a weighted average.
+func (s *ContainerSelection) WeightOf(containerName string) int32 {
+	if s != nil {
+		for _, w := range s.Weights {
+			if w.ContainerName == containerName {
+				return w.Weight
+			}
+		}
+	}
+	return 1
+}
+
+// StabilizationWindow returns the configured stabilization window or zero if none is set.
+func (r *ModeSwitchRules) StabilizationWindow() time.Duration {
+	if r == nil || r.StabilizationWindowSeconds == nil {
+		return 0
+	}
+	return time.Duration(*r.StabilizationWindowSeconds) * time.Second
+}
+
+// Default durations of the handoff phase.
+const (
+	DefaultHandoffStepInterval = time.Minute
+	DefaultHandoffTimeout      = 10 * time.Minute
+)
+
+// GetHandoff returns the rules of the handoff phase or nil if none is configured.
+func (r *ModeSwitchRules) GetHandoff() *HandoffRules {
+	if r == nil {
+		return nil
+	}
+	return r.Handoff
+}
+
+// StepInterval returns the interval between two steps of the max replicas, falling back to DefaultHandoffStepInterval.
+func (h *HandoffRules) StepInterval() time.Duration {
+	if h.StepIntervalSeconds == nil {
+		return DefaultHandoffStepInterval
+	}
+	return time.Duration(*h.StepIntervalSeconds) * time.Second
+}
+
+// Timeout returns the duration after which the handoff is ended regardless, falling back to DefaultHandoffTimeout.
+func (h *HandoffRules) Timeout() time.Duration {
+	if h.TimeoutSeconds == nil {
+		return DefaultHandoffTimeout
+	}
+	return time.Duration(*h.TimeoutSeconds) * time.Second
+}
+
+// DefaultHPABaselineTimeout is the default duration the VPA updates running pods to the HPA baseline.
+const DefaultHPABaselineTimeout = 10 * time.Minute
+
+// Timeout returns the duration after which the VPA stops updating running pods to the baseline,
+// falling back to DefaultHPABaselineTimeout.
+func (b *HPABaseline) Timeout() time.Duration {
+	if b.TimeoutSeconds == nil {
+		return DefaultHPABaselineTimeout
+	}
+	return time.Duration(*b.TimeoutSeconds) * time.Second
+}
+
+// EvaluationInterval returns the interval after which the scaling decision is re-evaluated,
+// falling back to the given default if it is not set. Zero disables the periodic re-evaluation.
+func (b *CranePodAutoscalerBehavior) EvaluationInterval(defaultInterval time.Duration) time.Duration {
+	if b.EvaluationIntervalSeconds == nil {
+		return defaultInterval
+	}
+	return time.Duration(*b.EvaluationIntervalSeconds) * time.Second
+}
+
+// EffectivePassiveVPAUpdateMode returns the update mode of the VPA while the HPA is active.
+// Initial falls back to Off if the HPA scales on the utilization of a resource the VPA controls.
+// A frozen HPA baseline is first rolled out to the running pods and then only applied to new pods in Initial mode,
+// as the baseline does not change while the HPA is active.
+func (r *CranePodAutoscaler) EffectivePassiveVPAUpdateMode() vpav1.UpdateMode {
+	if baseline := r.Status.HPABaseline; baseline != nil {
+		if baseline.Applied {
+			return vpav1.UpdateModeInitial
+		}
+		if policy := r.Spec.VPA.UpdatePolicy; policy != nil && policy.UpdateMode != nil && *policy.UpdateMode == vpav1.UpdateModeInPlaceOrRecreate {
+			return vpav1.UpdateModeInPlaceOrRecreate
+		}
+		return vpav1.UpdateModeRecreate
+	}
+	if r.Spec.Behavior.PassiveVPAUpdateMode == PassiveVPAUpdateModeInitial && len(r.UtilizationResourcesControlledByVPA()) == 0 {
+		return vpav1.UpdateModeInitial
+	}
+	return vpav1.UpdateModeOff
+}
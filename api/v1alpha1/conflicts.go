@@ -0,0 +1,118 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	hpav2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConflictingAutoscaler is an autoscaler that targets the same workload as a CranePodAutoscaler.
+// +kubebuilder:object:generate=false
+type ConflictingAutoscaler struct {
+	Kind              string
+	Name              string
+	CreationTimestamp metav1.Time
+}
+
+// String returns the kind and name of the autoscaler, e.g. "HorizontalPodAutoscaler my-app".
+func (c ConflictingAutoscaler) String() string {
+	return c.Kind + " " + c.Name
+}
+
+// Precedes reports whether the autoscaler targeted the workload before the CranePodAutoscaler was created,
+// so that the workload stays with it. Autoscalers created in the same second are ordered by name.
+// A CranePodAutoscaler that is not created yet is preceded by all autoscalers.
+func (c ConflictingAutoscaler) Precedes(r *CranePodAutoscaler) bool {
+	if r.CreationTimestamp.IsZero() {
+		return true
+	}
+	if !c.CreationTimestamp.Equal(&r.CreationTimestamp) {
+		return c.CreationTimestamp.Before(&r.CreationTimestamp)
+	}
+	return c.Name < r.Name
+}
+
+// FindConflictingAutoscalers lists the HPAs, VPAs and other CranePodAutoscalers in the namespace of the
+// CranePodAutoscaler that target the same workload.
+// The HPA and VPA with the names generated for the CranePodAutoscaler are not reported, as they are managed or adopted by it.
+// Neither are HPAs and VPAs controlled by another CranePodAutoscaler, which is reported itself instead.
+func (r *CranePodAutoscaler) FindConflictingAutoscalers(ctx context.Context, c client.Reader) ([]ConflictingAutoscaler, error) {
+	ref := r.Spec.HPA.ScaleTargetRef
+	target := targetKey(ref.APIVersion, ref.Kind, ref.Name)
+	var conflicts []ConflictingAutoscaler
+
+	craneAutoscalers := &CranePodAutoscalerList{}
+	if err := c.List(ctx, craneAutoscalers, client.InNamespace(r.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, other := range craneAutoscalers.Items {
+		ref := other.Spec.HPA.ScaleTargetRef
+		if other.Name != r.Name && other.DeletionTimestamp.IsZero() && targetKey(ref.APIVersion, ref.Kind, ref.Name) == target {
+			conflicts = append(conflicts, ConflictingAutoscaler{Kind: "CranePodAutoscaler", Name: other.Name, CreationTimestamp: other.CreationTimestamp})
+		}
+	}
+
+	hpas := &hpav2.HorizontalPodAutoscalerList{}
+	if err := c.List(ctx, hpas, client.InNamespace(r.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, hpa := range hpas.Items {
+		if r.managesAutoscaler(&hpa.ObjectMeta, r.HPAName()) {
+			continue
+		}
+		ref := hpa.Spec.ScaleTargetRef
+		if targetKey(ref.APIVersion, ref.Kind, ref.Name) == target {
+			conflicts = append(conflicts, ConflictingAutoscaler{Kind: "HorizontalPodAutoscaler", Name: hpa.Name, CreationTimestamp: hpa.CreationTimestamp})
+		}
+	}
+
+	vpas := &vpav1.VerticalPodAutoscalerList{}
+	if err := c.List(ctx, vpas, client.InNamespace(r.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, vpa := range vpas.Items {
+		if r.managesAutoscaler(&vpa.ObjectMeta, r.VPAName()) || vpa.Spec.TargetRef == nil {
+			continue
+		}
+		ref := vpa.Spec.TargetRef
+		if targetKey(ref.APIVersion, ref.Kind, ref.Name) == target {
+			conflicts = append(conflicts, ConflictingAutoscaler{Kind: "VerticalPodAutoscaler", Name: vpa.Name, CreationTimestamp: vpa.CreationTimestamp})
+		}
+	}
+	return conflicts, nil
+}
+
+// managesAutoscaler returns whether the HPA or VPA is excluded from the conflict detection because it is
+// has the name generated for the CranePodAutoscaler or is controlled by a CranePodAutoscaler.
+func (r *CranePodAutoscaler) managesAutoscaler(obj *metav1.ObjectMeta, generatedName string) bool {
+	if obj.Name == generatedName {
+		return true
+	}
+	owner := metav1.GetControllerOfNoCopy(obj)
+	return owner != nil && owner.Kind == "CranePodAutoscaler" && owner.APIVersion == GroupVersion.String()
+}
+
+// targetKey identifies the workload an object reference points to, ignoring the version of its API group.
+func targetKey(apiVersion, kind, name string) string {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return apiVersion + "/" + kind + "/" + name
+	}
+	return gv.Group + "/" + kind + "/" + name
+}
+
+// DescribeConflicts returns a human-readable description of the autoscalers conflicting with the CranePodAutoscaler.
+func (r *CranePodAutoscaler) DescribeConflicts(conflicts []ConflictingAutoscaler) string {
+	target := r.Spec.HPA.ScaleTargetRef
+	names := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		names = append(names, conflict.String())
+	}
+	return fmt.Sprintf("%s %s is already targeted by %s", target.Kind, target.Name, strings.Join(names, ", "))
+}
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	hpav2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 )
@@ -29,9 +30,117 @@ type CranePodAutoscalerSpec struct {
 	HPA      hpav2.HorizontalPodAutoscalerSpec `json:"hpa"`
 	VPA      vpav1.VerticalPodAutoscalerSpec   `json:"vpa"`
 	Behavior CranePodAutoscalerBehavior        `json:"behavior"`
+
+	// Mode overrides the automatic switching between the autoscalers, e.g. during an incident or a load test.
+	// Auto (default) switches automatically. PinnedHPA and PinnedVPA keep the respective autoscaler active.
+	// Suspended leaves both autoscalers untouched. Split keeps both autoscalers active, with the VPA only
+	// controlling the resources the HPA metrics do not use.
+	// +kubebuilder:default=Auto
+	// +optional
+	Mode Mode `json:"mode,omitempty"`
+
+	// DeletionPolicy defines what happens to the autoscaling of the target when the CranePodAutoscaler is deleted.
+	// Delete (default) deletes the HPA and VPA. OrphanActiveHPA keeps an enabled HPA and deletes the VPA.
+	// RestoreReplicas deletes the HPA and VPA and scales the target to restoreReplicas.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// Number of replicas the target is scaled to on deletion if deletionPolicy is RestoreReplicas.
+	// +optional
+	RestoreReplicas *int32 `json:"restoreReplicas,omitempty"`
+
+	// AdoptExisting allows taking over an existing HPA or VPA with the name generated for the CranePodAutoscaler
+	// that is not controlled by anyone else and targets the same workload.
+	// Without it such objects are reported as a conflict and left untouched.
+	// +optional
+	AdoptExisting bool `json:"adoptExisting,omitempty"`
+
+	// Template for the HPA generated for the CranePodAutoscaler.
+	// +optional
+	HPATemplate *AutoscalerTemplate `json:"hpaTemplate,omitempty"`
+
+	// Template for the VPA generated for the CranePodAutoscaler.
+	// +optional
+	VPATemplate *AutoscalerTemplate `json:"vpaTemplate,omitempty"`
+}
+
+// AutoscalerTemplate configures an HPA or VPA generated for a CranePodAutoscaler.
+type AutoscalerTemplate struct {
+	// Metadata of the generated object.
+	// +optional
+	Metadata AutoscalerTemplateMetadata `json:"metadata,omitempty"`
+}
+
+// AutoscalerTemplateMetadata is the metadata of a generated HPA or VPA.
+type AutoscalerTemplateMetadata struct {
+	// +kubebuilder:validation:MaxLength=63
+	// Suffix appended to the name of the CranePodAutoscaler to form the name of the generated object, e.g. "-hpa".
+	// Cannot be changed once the object was created.
+	// +optional
+	NameSuffix string `json:"nameSuffix,omitempty"`
+
+	// Labels of the generated object, e.g. for cost allocation or policy engines.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations of the generated object.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// DeletionPolicy defines what happens to the autoscaling of the target when the CranePodAutoscaler is deleted.
+// +kubebuilder:validation:Enum=Delete;OrphanActiveHPA;RestoreReplicas
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the HPA and VPA together with the CranePodAutoscaler.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphanActiveHPA keeps an enabled HPA and deletes the VPA.
+	DeletionPolicyOrphanActiveHPA DeletionPolicy = "OrphanActiveHPA"
+	// DeletionPolicyRestoreReplicas deletes the HPA and VPA and scales the target to a fixed number of replicas.
+	DeletionPolicyRestoreReplicas DeletionPolicy = "RestoreReplicas"
+)
+
+// Mode defines whether the autoscalers are switched automatically.
+// +kubebuilder:validation:Enum=Auto;PinnedHPA;PinnedVPA;Suspended;Split
+type Mode string
+
+const (
+	// ModeAuto switches between the autoscalers automatically.
+	ModeAuto Mode = "Auto"
+	// ModePinnedHPA keeps the HPA active and the VPA disabled.
+	ModePinnedHPA Mode = "PinnedHPA"
+	// ModePinnedVPA keeps the VPA active and the HPA disabled.
+	ModePinnedVPA Mode = "PinnedVPA"
+	// ModeSuspended leaves both autoscalers untouched.
+	ModeSuspended Mode = "Suspended"
+	// ModeSplit keeps both autoscalers active. The VPA only controls the resources the HPA metrics do not use,
+	// e.g. memory while the HPA scales on CPU.
+	ModeSplit Mode = "Split"
+)
+
+// ModeChangedByAnnotation can be set together with spec.mode to record who overrode the automatic switching
+// and why, e.g. "jane: load test". Without it the field manager that last set spec.mode is reported.
+const ModeChangedByAnnotation = "autoscaling.phihos.github.io/mode-changed-by"
+
 type CranePodAutoscalerBehavior struct {
+	// Strategy that decides which autoscaler is active in Auto mode. Threshold (default) switches based on
+	// the thresholds below and the min replicas of the HPA. Rules switches based on the CEL expressions in rules.
+	// Further strategies can be registered with the controller.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[A-Za-z][A-Za-z0-9]*$`
+	// +optional
+	Strategy DecisionStrategyName `json:"strategy,omitempty"`
+
+	// CEL expressions that switch between the autoscalers if strategy is Rules.
+	// While one autoscaler is active, the rules switching to the other one are evaluated
+	// and the first rule that evaluates to true switches.
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Rules []SwitchingRule `json:"rules,omitempty"`
+
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=100
 	// +kubebuilder:validation:ExclusiveMaximum=false
@@ -39,7 +148,269 @@ type CranePodAutoscalerBehavior struct {
 	// Exceeding this threshold will cause autoscaling to switch from vertical to horizontal autoscaling.
 	// Falling below this threshold will cause autoscaling to switch from horizontal to vertical autoscaling
 	// if the HPA scaled down to min replicas.
+	// Serves as the default for vpaToHpaThresholdPercent and hpaToVpaThresholdPercent.
 	VPACapacityThresholdPercent int32 `json:"vpaCapacityThresholdPercent,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// Percentage of the VPA target and the upper bound above which autoscaling switches from vertical to
+	// horizontal autoscaling. Defaults to vpaCapacityThresholdPercent.
+	VPAToHPAThresholdPercent int32 `json:"vpaToHpaThresholdPercent,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// Percentage of the VPA target and the upper bound at or below which autoscaling switches from horizontal
+	// to vertical autoscaling if the HPA scaled down to min replicas.
+	// Must not be greater than vpaToHpaThresholdPercent. The gap between both thresholds forms a hysteresis band
+	// that prevents workloads near the boundary from flapping between modes.
+	// Defaults to the lower of vpaCapacityThresholdPercent and vpaToHpaThresholdPercent.
+	HPAToVPAThresholdPercent int32 `json:"hpaToVpaThresholdPercent,omitempty"`
+
+	// Thresholds for individual resources, optionally limited to a single container.
+	// They override the thresholds above. An entry for a specific container takes precedence
+	// over an entry for all containers.
+	// +optional
+	ResourceThresholds []ResourceThreshold `json:"resourceThresholds,omitempty"`
+
+	// Selection of the containers considered for the switching decision
+	// and how their utilizations are aggregated. By default all containers are considered
+	// and the container resource furthest above its threshold decides.
+	// +optional
+	Containers *ContainerSelection `json:"containers,omitempty"`
+
+	// Rules for switching from vertical to horizontal autoscaling.
+	// +optional
+	SwitchToHPA *ModeSwitchRules `json:"switchToHPA,omitempty"`
+
+	// Rules for switching from horizontal to vertical autoscaling.
+	// +optional
+	SwitchToVPA *ModeSwitchRules `json:"switchToVPA,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=86400
+	// Number of seconds after which the scaling decision is re-evaluated even if neither
+	// the HPA nor the VPA changed. Defaults to the --default-evaluation-interval of the controller.
+	// 0 disables the periodic re-evaluation.
+	// +optional
+	EvaluationIntervalSeconds *int32 `json:"evaluationIntervalSeconds,omitempty"`
+
+	// Update mode of the VPA while the HPA is active. Off (default) leaves the requests of all pods untouched.
+	// Initial applies the VPA recommendation to pods created while the HPA is active, e.g. on scale-out,
+	// without evicting running pods. Initial falls back to Off if the HPA scales on the utilization of a resource
+	// the VPA controls, as changing its requests would change the utilization the HPA observes.
+	// +optional
+	PassiveVPAUpdateMode PassiveVPAUpdateMode `json:"passiveVpaUpdateMode,omitempty"`
+
+	// Baseline requests applied while the HPA is active after a switch from VPA, so that the HPA computes
+	// utilization against a stable baseline instead of the requests the VPA applied last.
+	// The HPA is enabled right away, while the VPA updates the running pods to the baseline, in InPlaceOrRecreate mode
+	// if spec.vpa uses it and in Recreate mode otherwise. Once all pods carry the baseline, the passive VPA applies the
+	// baseline in Initial mode to pods created later, e.g. on scale-out.
+	// Without it pods keep the requests the VPA applied last.
+	// +optional
+	HPABaseline *HPABaseline `json:"hpaBaseline,omitempty"`
+}
+
+// DecisionStrategyName is the name of a strategy that decides which autoscaler is active.
+type DecisionStrategyName string
+
+const (
+	// DecisionStrategyThreshold switches to HPA once a utilization of the VPA recommendation exceeds its threshold
+	// and back to VPA once the HPA is at min replicas and all utilizations are at or below their thresholds.
+	DecisionStrategyThreshold DecisionStrategyName = "Threshold"
+	// DecisionStrategyRules switches once one of the CEL expressions in spec.behavior.rules evaluates to true.
+	DecisionStrategyRules DecisionStrategyName = "Rules"
+)
+
+// SwitchingRule switches to an autoscaler once its CEL expression evaluates to true.
+type SwitchingRule struct {
+	// +kubebuilder:validation:Enum=HPA;VPA
+	// Autoscaler the rule switches to. The rule is only evaluated while the other autoscaler is active.
+	SwitchTo string `json:"switchTo"`
+
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	// CEL expression returning a bool, e.g. "vpa.maxUtilization('memory') > 0.9 || hpa.desiredReplicas > 8".
+	// The variables hpa, vpa and target describe the current state, see the README for their fields.
+	Expression string `json:"expression"`
+}
+
+// HPABaselineSource defines where the requests applied while the HPA is active come from.
+// +kubebuilder:validation:Enum=Target;UpperBound;Explicit
+type HPABaselineSource string
+
+const (
+	// HPABaselineSourceTarget freezes the VPA target recommendation at the time of the switch.
+	HPABaselineSourceTarget HPABaselineSource = "Target"
+	// HPABaselineSourceUpperBound freezes the VPA upper bound at the time of the switch.
+	HPABaselineSourceUpperBound HPABaselineSource = "UpperBound"
+	// HPABaselineSourceExplicit uses explicitly configured requests.
+	HPABaselineSourceExplicit HPABaselineSource = "Explicit"
+)
+
+// HPABaseline configures the requests applied to the pods while the HPA is active.
+type HPABaseline struct {
+	// Source of the baseline. Target and UpperBound freeze the respective VPA recommendation at the time
+	// of the switch to HPA. Explicit uses the requests given in containers.
+	Source HPABaselineSource `json:"source"`
+
+	// Requests per container if source is Explicit.
+	// +optional
+	Containers []ContainerRequests `json:"containers,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=86400
+	// Number of seconds after which the VPA stops updating running pods to the baseline.
+	// Defaults to 600.
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// ContainerRequests are the requests of one container.
+type ContainerRequests struct {
+	// Name of the container.
+	ContainerName string `json:"containerName"`
+
+	// Requests of the container.
+	Requests corev1.ResourceList `json:"requests"`
+}
+
+// PassiveVPAUpdateMode is the update mode of the VPA while the HPA is active.
+// +kubebuilder:validation:Enum=Off;Initial
+type PassiveVPAUpdateMode string
+
+const (
+	// PassiveVPAUpdateModeOff leaves the requests of all pods untouched.
+	PassiveVPAUpdateModeOff PassiveVPAUpdateMode = "Off"
+	// PassiveVPAUpdateModeInitial applies the VPA recommendation to newly created pods only.
+	PassiveVPAUpdateModeInitial PassiveVPAUpdateMode = "Initial"
+)
+
+// ResourceThreshold overrides the switching thresholds for one resource.
+type ResourceThreshold struct {
+	// +kubebuilder:validation:Enum=cpu;memory
+	// Name of the resource the thresholds apply to.
+	Resource corev1.ResourceName `json:"resource"`
+
+	// Name of the container the thresholds apply to. Applies to all containers if empty.
+	// +optional
+	ContainerName string `json:"containerName,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// Threshold for switching from vertical to horizontal autoscaling.
+	// Defaults to the global vpaToHpaThresholdPercent.
+	VPAToHPAThresholdPercent int32 `json:"vpaToHpaThresholdPercent,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// Threshold for switching from horizontal to vertical autoscaling.
+	// Defaults to the lower of the global hpaToVpaThresholdPercent and vpaToHpaThresholdPercent of this entry.
+	HPAToVPAThresholdPercent int32 `json:"hpaToVpaThresholdPercent,omitempty"`
+}
+
+// ContainerAggregation defines how the utilizations of multiple containers are aggregated.
+// +kubebuilder:validation:Enum=Max;WeightedAverage;MainContainer
+type ContainerAggregation string
+
+const (
+	// ContainerAggregationMax lets the container resource furthest above its threshold decide.
+	ContainerAggregationMax ContainerAggregation = "Max"
+	// ContainerAggregationWeightedAverage lets the weighted average utilization per resource decide.
+	ContainerAggregationWeightedAverage ContainerAggregation = "WeightedAverage"
+	// ContainerAggregationMainContainer lets only the main container decide.
+	ContainerAggregationMainContainer ContainerAggregation = "MainContainer"
+)
+
+// ContainerSelection selects containers and configures how their utilizations are aggregated.
+type ContainerSelection struct {
+	// Glob patterns of container names to consider. All containers are considered if empty.
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Glob patterns of container names to ignore, e.g. sidecars like "istio-proxy".
+	// Takes precedence over include.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// How the utilizations of the selected containers are aggregated. Defaults to Max.
+	// Max lets the container resource furthest above its threshold decide.
+	// WeightedAverage lets the weighted average utilization of each resource decide.
+	// MainContainer lets only the container named in mainContainer decide.
+	// +optional
+	Aggregation ContainerAggregation `json:"aggregation,omitempty"`
+
+	// Name of the container that decides if aggregation is MainContainer.
+	// +optional
+	MainContainer string `json:"mainContainer,omitempty"`
+
+	// Weights of containers for aggregation WeightedAverage. Containers without weight have a weight of 1.
+	// +optional
+	Weights []ContainerWeight `json:"weights,omitempty"`
+}
+
+// ContainerWeight is the weight of a container in a weighted average.
+type ContainerWeight struct {
+	// Name of the container.
+	ContainerName string `json:"containerName"`
+
+	// +kubebuilder:validation:Minimum=0
+	// Weight of the container.
+	Weight int32 `json:"weight"`
+}
+
+// ModeSwitchRules configures how the switch into one autoscaling mode is performed.
+type ModeSwitchRules struct {
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600
+	// Number of seconds for which the switching condition must hold continuously
+	// before the switch is performed. Defaults to 0 (switch immediately).
+	// +optional
+	StabilizationWindowSeconds *int32 `json:"stabilizationWindowSeconds,omitempty"`
+
+	// Handoff phase that is passed before vertical autoscaling is declared. Only supported in switchToVPA.
+	// Without it the HPA is disabled and the VPA enabled at the same moment.
+	// +optional
+	Handoff *HandoffRules `json:"handoff,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// Percentage of its target that every HPA metric must be at or below before switching, e.g. 70 holds back
+	// the switch while the HPA observes a CPU utilization of 60% with a target of 80%. The switch is also held back
+	// while the HPA does not report a current value for one of its metrics. Only supported in switchToVPA.
+	// +optional
+	HPAMetricThresholdPercent *int32 `json:"hpaMetricThresholdPercent,omitempty"`
+}
+
+// HandoffRules configures the handoff phase from horizontal to vertical autoscaling.
+// The HPA stays in charge during the handoff, so the switch is aborted if its condition stops holding.
+type HandoffRules struct {
+	// +kubebuilder:validation:Minimum=1
+	// Number of replicas by which the max replicas of the HPA are lowered per step, starting at its current replicas,
+	// until min replicas are reached. The max replicas are not stepped down if unset.
+	// +optional
+	MaxReplicasStep *int32 `json:"maxReplicasStep,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3600
+	// Number of seconds between two steps. Defaults to 60.
+	// +optional
+	StepIntervalSeconds *int32 `json:"stepIntervalSeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// Percentage of the pods of the target whose requests must lie within the bounds recommended by the VPA
+	// before vertical autoscaling is declared. The HPA is kept at min replicas and the VPA is enabled meanwhile.
+	// Not waited for if unset.
+	// +optional
+	UpdatedPodsPercent *int32 `json:"updatedPodsPercent,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=86400
+	// Number of seconds after which vertical autoscaling is declared even if the handoff did not complete.
+	// Defaults to 600.
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
 }
 
 // CranePodAutoscalerStatus defines the observed state of CranePodAutoscaler
@@ -55,10 +426,182 @@ type CranePodAutoscalerStatus struct {
 
 	// Conditions store the status conditions of the CraneAutoscaler instances
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// Autoscaler that is currently active. One of "HPA" or "VPA", or "Split" if both are active in Split mode.
+	// +optional
+	ActiveAutoscaler string `json:"activeAutoscaler,omitempty"`
+
+	// Utilization of the most critical container resource in percent of its VPA upper bound.
+	// +optional
+	CurrentUtilizationPercent *int32 `json:"currentUtilizationPercent,omitempty"`
+
+	// Threshold in percent that the current utilization is compared against for switching away
+	// from the active autoscaler.
+	// +optional
+	ThresholdPercent *int32 `json:"thresholdPercent,omitempty"`
+
+	// Current number of replicas as observed by the HPA.
+	// +optional
+	HPACurrentReplicas int32 `json:"hpaCurrentReplicas,omitempty"`
+
+	// Desired number of replicas as calculated by the HPA.
+	// +optional
+	HPADesiredReplicas int32 `json:"hpaDesiredReplicas,omitempty"`
+
+	// Current values of the HPA metrics relative to their targets, as reported by the HPA.
+	// +optional
+	HPAMetrics []HPAMetricStatus `json:"hpaMetrics,omitempty"`
+
+	// Summary of the VPA target recommendation per container.
+	// +optional
+	VPATarget string `json:"vpaTarget,omitempty"`
+
+	// Container whose utilization triggered the last switch between autoscalers.
+	// Empty if the switch was triggered by a weighted average over all containers.
+	// +optional
+	TriggeringContainer string `json:"triggeringContainer,omitempty"`
+
+	// Resource whose utilization triggered the last switch between autoscalers.
+	// +optional
+	TriggeringResource corev1.ResourceName `json:"triggeringResource,omitempty"`
+
+	// Time of the last switch between autoscalers.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Generation of the CranePodAutoscaler that was last reconciled successfully.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Most recent switches between autoscalers, oldest first.
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	Transitions []AutoscalerTransition `json:"transitions,omitempty"`
+
+	// PendingSwitch records a mode switch whose condition currently holds,
+	// but whose stabilization window has not passed yet.
+	// +optional
+	PendingSwitch *PendingModeSwitch `json:"pendingSwitch,omitempty"`
+
+	// Override is set while spec.mode overrides the automatic switching.
+	// +optional
+	Override *ModeOverride `json:"override,omitempty"`
+
+	// Handoff records the progress of a handoff from horizontal to vertical autoscaling.
+	// +optional
+	Handoff *HandoffStatus `json:"handoff,omitempty"`
+
+	// HPABaseline records the baseline requests applied while the HPA is active, frozen at the last switch from VPA.
+	// +optional
+	HPABaseline *HPABaselineStatus `json:"hpaBaseline,omitempty"`
+}
+
+// HPAMetricStatus is the current value of an HPA metric relative to its target.
+type HPAMetricStatus struct {
+	// Type of the metric source, e.g. Resource or Pods.
+	Type hpav2.MetricSourceType `json:"type"`
+	// Name of the metric, e.g. "cpu" for a resource metric. Prefixed by the container for a container resource metric.
+	Name string `json:"name"`
+	// Current value as reported by the HPA, e.g. "60%" for a utilization or "250m" for a value.
+	Current string `json:"current"`
+	// Target value from the HPA spec.
+	Target string `json:"target"`
+	// Current value in percent of the target.
+	PercentOfTarget int32 `json:"percentOfTarget"`
+}
+
+// MaxTransitionHistory is the maximum number of transitions kept in the status.
+const MaxTransitionHistory = 10
+
+// AutoscalerTransition records a switch between autoscalers.
+type AutoscalerTransition struct {
+	// Autoscaler that was active before the switch. Empty for the initial decision.
+	// +optional
+	From string `json:"from,omitempty"`
+	// Autoscaler that was active after the switch.
+	To string `json:"to"`
+	// Time of the switch.
+	Time metav1.Time `json:"time"`
+	// Human-readable reason for the switch.
+	Reason string `json:"reason"`
+}
+
+// PendingModeSwitch describes a mode switch that waits for its stabilization window to pass.
+type PendingModeSwitch struct {
+	// Autoscaler that will be activated once the stabilization window has passed.
+	To string `json:"to"`
+	// Time at which the switching condition was first observed.
+	Since metav1.Time `json:"since"`
+}
+
+// HandoffPhase is the phase of a handoff from horizontal to vertical autoscaling.
+type HandoffPhase string
+
+const (
+	// HandoffPhaseSteppingDown lowers the max replicas of the HPA step by step.
+	HandoffPhaseSteppingDown HandoffPhase = "SteppingDown"
+	// HandoffPhaseWaitingForPodUpdates keeps the HPA at min replicas and waits for the VPA to update the pods.
+	HandoffPhaseWaitingForPodUpdates HandoffPhase = "WaitingForPodUpdates"
+)
+
+// HandoffStatus describes the progress of a handoff from horizontal to vertical autoscaling.
+type HandoffStatus struct {
+	// Current phase of the handoff.
+	Phase HandoffPhase `json:"phase"`
+	// Time at which the handoff started.
+	StartTime metav1.Time `json:"startTime"`
+	// Max replicas the HPA is limited to.
+	MaxReplicas int32 `json:"maxReplicas"`
+	// Time at which the max replicas were last lowered.
+	// +optional
+	LastStepTime *metav1.Time `json:"lastStepTime,omitempty"`
+	// Number of pods whose requests lie within the bounds recommended by the VPA.
+	// +optional
+	UpdatedPods int32 `json:"updatedPods,omitempty"`
+	// Number of pods of the target.
+	// +optional
+	TotalPods int32 `json:"totalPods,omitempty"`
+}
+
+// HPABaselineStatus describes the baseline requests applied while the HPA is active.
+type HPABaselineStatus struct {
+	// Source the baseline was taken from.
+	Source HPABaselineSource `json:"source"`
+	// Requests per container.
+	Containers []ContainerRequests `json:"containers"`
+	// Time at which the baseline was frozen.
+	Time metav1.Time `json:"time"`
+	// Whether the running pods were updated to the baseline, or the timeout passed, so that the VPA only applies it to new pods.
+	// +optional
+	Applied bool `json:"applied,omitempty"`
+	// Number of pods whose requests are close to the baseline.
+	// +optional
+	UpdatedPods int32 `json:"updatedPods,omitempty"`
+	// Number of pods of the target.
+	// +optional
+	TotalPods int32 `json:"totalPods,omitempty"`
+}
+
+// ModeOverride describes a manual override of the automatic switching.
+type ModeOverride struct {
+	// Mode that overrides the automatic switching.
+	Mode Mode `json:"mode"`
+	// Who set the mode, taken from the mode-changed-by annotation or the field manager of spec.mode.
+	// +optional
+	By string `json:"by,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`,description="Whether the autoscalers are switched automatically",priority=1
+// +kubebuilder:printcolumn:name="Active",type=string,JSONPath=`.status.activeAutoscaler`,description="Autoscaler that is currently active"
+// +kubebuilder:printcolumn:name="Utilization",type=integer,JSONPath=`.status.currentUtilizationPercent`,description="Utilization of the most critical container resource in percent of its VPA upper bound"
+// +kubebuilder:printcolumn:name="Threshold",type=integer,JSONPath=`.status.thresholdPercent`,description="Threshold in percent for switching away from the active autoscaler"
+// +kubebuilder:printcolumn:name="Current",type=integer,JSONPath=`.status.hpaCurrentReplicas`,description="Current number of replicas as observed by the HPA"
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.hpaDesiredReplicas`,description="Desired number of replicas as calculated by the HPA"
+// +kubebuilder:printcolumn:name="VPA Target",type=string,JSONPath=`.status.vpaTarget`,description="VPA target recommendation per container",priority=1
+// +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // CranePodAutoscaler is the Schema for the cranepodautoscalers API
 type CranePodAutoscaler struct {
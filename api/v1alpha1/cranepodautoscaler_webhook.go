@@ -18,8 +18,11 @@ package v1alpha1
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -31,7 +34,7 @@ var cranepodautoscalerlog = logf.Log.WithName("cranepodautoscaler-resource")
 func (r *CranePodAutoscaler) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr, &CranePodAutoscaler{}).
 		WithDefaulter(&cranePodAutoscalerDefaulter{}).
-		WithValidator(&cranePodAutoscalerValidator{}).
+		WithValidator(&cranePodAutoscalerValidator{Client: mgr.GetAPIReader()}).
 		Complete()
 }
 
@@ -49,6 +52,8 @@ func (d *cranePodAutoscalerDefaulter) Default(_ context.Context, obj *CranePodAu
 	if obj.Spec.Behavior.VPACapacityThresholdPercent == 0 {
 		obj.Spec.Behavior.VPACapacityThresholdPercent = 80
 	}
+	// vpaToHpaThresholdPercent and hpaToVpaThresholdPercent are left unset on purpose. They are resolved
+	// at runtime, so that they keep following vpaCapacityThresholdPercent when it is changed later.
 	return nil
 }
 
@@ -57,22 +62,58 @@ func (d *cranePodAutoscalerDefaulter) Default(_ context.Context, obj *CranePodAu
 // +kubebuilder:webhook:path=/validate-autoscaling-phihos-github-io-v1alpha1-cranepodautoscaler,mutating=false,failurePolicy=fail,sideEffects=None,groups=autoscaling.phihos.github.io,resources=cranepodautoscalers,verbs=create;update,versions=v1alpha1,name=vcranepodautoscaler.kb.io,admissionReviewVersions=v1
 
 // cranePodAutoscalerValidator implements admission.Validator for CranePodAutoscaler.
-type cranePodAutoscalerValidator struct{}
+type cranePodAutoscalerValidator struct {
+	// Client is used to find other autoscalers targeting the same workload.
+	Client client.Reader
+}
 
 var _ admission.Validator[*CranePodAutoscaler] = &cranePodAutoscalerValidator{}
 
 // ValidateCreate implements admission.Validator so a webhook will be registered for the type.
-func (v *cranePodAutoscalerValidator) ValidateCreate(_ context.Context, obj *CranePodAutoscaler) (admission.Warnings, error) {
+func (v *cranePodAutoscalerValidator) ValidateCreate(ctx context.Context, obj *CranePodAutoscaler) (admission.Warnings, error) {
 	cranepodautoscalerlog.Info("validate create", "name", obj.Name)
 
-	return nil, obj.Validate()
+	if err := obj.Validate(); err != nil {
+		return nil, err
+	}
+	return obj.Warnings(), v.validateNoConflicts(ctx, obj)
 }
 
 // ValidateUpdate implements admission.Validator so a webhook will be registered for the type.
-func (v *cranePodAutoscalerValidator) ValidateUpdate(_ context.Context, _, obj *CranePodAutoscaler) (admission.Warnings, error) {
+func (v *cranePodAutoscalerValidator) ValidateUpdate(ctx context.Context, oldObj, obj *CranePodAutoscaler) (admission.Warnings, error) {
 	cranepodautoscalerlog.Info("validate update", "name", obj.Name)
 
-	return nil, obj.Validate()
+	if err := obj.Validate(); err != nil {
+		return nil, err
+	}
+	// Renaming would leave the previously generated objects behind.
+	if oldObj.HPAName() != obj.HPAName() {
+		return nil, errors.New("spec.hpaTemplate.metadata.nameSuffix is immutable")
+	}
+	if oldObj.VPAName() != obj.VPAName() {
+		return nil, errors.New("spec.vpaTemplate.metadata.nameSuffix is immutable")
+	}
+	// Only a changed target can introduce a new conflict. Checking unchanged targets would block
+	// unrelated updates, e.g. removing finalizers, once a conflicting autoscaler has been created.
+	if oldObj.Spec.HPA.ScaleTargetRef == obj.Spec.HPA.ScaleTargetRef {
+		return obj.Warnings(), nil
+	}
+	return obj.Warnings(), v.validateNoConflicts(ctx, obj)
+}
+
+// validateNoConflicts rejects a CranePodAutoscaler whose target is already scaled by another autoscaler.
+func (v *cranePodAutoscalerValidator) validateNoConflicts(ctx context.Context, obj *CranePodAutoscaler) error {
+	if v.Client == nil {
+		return nil
+	}
+	conflicts, err := obj.FindConflictingAutoscalers(ctx, v.Client)
+	if err != nil {
+		return fmt.Errorf("failed to look up conflicting autoscalers: %w", err)
+	}
+	if len(conflicts) > 0 {
+		return errors.New(obj.DescribeConflicts(conflicts))
+	}
+	return nil
 }
 
 // ValidateDelete implements admission.Validator so a webhook will be registered for the type.
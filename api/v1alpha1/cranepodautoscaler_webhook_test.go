@@ -21,6 +21,7 @@ import (
 	. "github.com/onsi/gomega"
 	autoscaling "k8s.io/api/autoscaling/v1"
 	hpav2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 	"k8s.io/utils/ptr"
 
@@ -85,6 +86,456 @@ var _ = Describe("CranePodAutoscaler Webhook", func() {
 			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
 		})
 
+		It("Should deny if the HPA to VPA threshold is above the VPA to HPA threshold", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					Behavior: CranePodAutoscalerBehavior{
+						VPAToHPAThresholdPercent: 70,
+						HPAToVPAThresholdPercent: 80,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
+		})
+
+		It("Should deny duplicate resource thresholds", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					Behavior: CranePodAutoscalerBehavior{
+						ResourceThresholds: []ResourceThreshold{
+							{Resource: corev1.ResourceMemory, VPAToHPAThresholdPercent: 95},
+							{Resource: corev1.ResourceMemory, VPAToHPAThresholdPercent: 90},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
+		})
+
+		It("Should deny aggregation MainContainer without a main container", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					Behavior: CranePodAutoscalerBehavior{
+						Containers: &ContainerSelection{
+							Aggregation: ContainerAggregationMainContainer,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
+		})
+
+		It("Should deny container weights that are all zero", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					Behavior: CranePodAutoscalerBehavior{
+						Containers: &ContainerSelection{
+							Aggregation: ContainerAggregationWeightedAverage,
+							Weights:     []ContainerWeight{{ContainerName: "app", Weight: 0}},
+						},
+					},
+				},
+			}
+			err := k8sClient.Create(ctx, resource)
+			Expect(err).To(MatchError(ContainSubstring("spec.Behavior.containers.weights must contain at least one positive weight")))
+		})
+
+		It("Should deny deletion policy RestoreReplicas without restore replicas", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					DeletionPolicy: DeletionPolicyRestoreReplicas,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
+		})
+
+		It("Should deny a name suffix resulting in an invalid name", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					HPATemplate: &AutoscalerTemplate{
+						Metadata: AutoscalerTemplateMetadata{NameSuffix: "_HPA"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
+		})
+
+		It("Should deny a handoff when switching to HPA", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					Behavior: CranePodAutoscalerBehavior{
+						SwitchToHPA: &ModeSwitchRules{Handoff: &HandoffRules{MaxReplicasStep: ptr.To[int32](1)}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
+		})
+
+		It("Should deny an HPA metric threshold when switching to HPA", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					Behavior: CranePodAutoscalerBehavior{
+						SwitchToHPA: &ModeSwitchRules{HPAMetricThresholdPercent: ptr.To[int32](50)},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
+		})
+
+		It("Should deny an explicit HPA baseline without requests", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					Behavior: CranePodAutoscalerBehavior{
+						HPABaseline: &HPABaseline{Source: HPABaselineSourceExplicit},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
+		})
+
+		It("Should deny Split mode if the VPA controls a resource the HPA metrics use", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+						Metrics: []hpav2.MetricSpec{{
+							Type: hpav2.ResourceMetricSourceType,
+							Resource: &hpav2.ResourceMetricSource{
+								Name:   corev1.ResourceCPU,
+								Target: hpav2.MetricTarget{Type: hpav2.UtilizationMetricType, AverageUtilization: ptr.To[int32](70)},
+							},
+						}},
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						ResourcePolicy: &vpav1.PodResourcePolicy{ContainerPolicies: []vpav1.ContainerResourcePolicy{{
+							ContainerName:       "*",
+							ControlledResources: &[]corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+						}}},
+					},
+					Mode: ModeSplit,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).NotTo(Succeed())
+		})
+
+		It("Should deny a switching rule that does not type-check", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "some-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					Behavior: CranePodAutoscalerBehavior{
+						Strategy: DecisionStrategyRules,
+						Rules: []SwitchingRule{
+							{SwitchTo: "HPA", Expression: "vpa.maxUtilization('memory') > 0.9 || hpa.desiredReplicas > 8"},
+							{SwitchTo: "VPA", Expression: "hpa.desiredReplicas"},
+						},
+					},
+				},
+			}
+			err := k8sClient.Create(ctx, resource)
+			Expect(err).To(MatchError(ContainSubstring("spec.Behavior.rules[1].expression is invalid: expression must return a bool")))
+		})
+
+		It("Should deny if another CranePodAutoscaler targets the same workload", func() {
+			newResource := func(name string) *CranePodAutoscaler {
+				return &CranePodAutoscaler{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: "default",
+					},
+					Spec: CranePodAutoscalerSpec{
+						HPA: hpav2.HorizontalPodAutoscalerSpec{
+							ScaleTargetRef: hpav2.CrossVersionObjectReference{
+								Kind:       "Deployment",
+								Name:       "duplicate-deployment",
+								APIVersion: "apps/v1",
+							},
+							MinReplicas: ptr.To[int32](1),
+							MaxReplicas: 20,
+						},
+						VPA: vpav1.VerticalPodAutoscalerSpec{
+							TargetRef: &autoscaling.CrossVersionObjectReference{
+								Kind:       "Deployment",
+								Name:       "duplicate-deployment",
+								APIVersion: "apps/v1",
+							},
+						},
+					},
+				}
+			}
+			first := newResource("test-resource-first")
+			Expect(k8sClient.Create(ctx, first)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, first)).To(Succeed())
+			})
+
+			err := k8sClient.Create(ctx, newResource("test-resource-second"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("CranePodAutoscaler test-resource-first"))
+		})
+
+		It("Should deny if a standalone HPA targets the same workload", func() {
+			hpa := &hpav2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "standalone-hpa",
+					Namespace: "default",
+				},
+				Spec: hpav2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: hpav2.CrossVersionObjectReference{
+						Kind:       "Deployment",
+						Name:       "standalone-deployment",
+						APIVersion: "apps/v1",
+					},
+					MaxReplicas: 5,
+				},
+			}
+			Expect(k8sClient.Create(ctx, hpa)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, hpa)).To(Succeed())
+			})
+
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource-standalone",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "standalone-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "standalone-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+				},
+			}
+			err := k8sClient.Create(ctx, resource)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("HorizontalPodAutoscaler standalone-hpa"))
+		})
+
 		It("Should admit if all required fields are provided", func() {
 			resource := &CranePodAutoscaler{
 				ObjectMeta: metav1.ObjectMeta{
@@ -116,4 +567,54 @@ var _ = Describe("CranePodAutoscaler Webhook", func() {
 			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 		})
 	})
+
+	Context("When updating CranePodAutoscaler under Defaulting Webhook", func() {
+		It("Should keep deriving the thresholds from vpaCapacityThresholdPercent", func() {
+			resource := &CranePodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-resource-capacity",
+					Namespace: "default",
+				},
+				Spec: CranePodAutoscalerSpec{
+					HPA: hpav2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: hpav2.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "capacity-deployment",
+							APIVersion: "apps/v1",
+						},
+						MinReplicas: ptr.To[int32](1),
+						MaxReplicas: 20,
+					},
+					VPA: vpav1.VerticalPodAutoscalerSpec{
+						TargetRef: &autoscaling.CrossVersionObjectReference{
+							Kind:       "Deployment",
+							Name:       "capacity-deployment",
+							APIVersion: "apps/v1",
+						},
+					},
+					Behavior: CranePodAutoscalerBehavior{
+						VPACapacityThresholdPercent: 80,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			})
+			Expect(resource.Spec.Behavior.VPAToHPAThresholdPercent).To(BeZero())
+			Expect(resource.Spec.Behavior.HPAToVPAThresholdPercent).To(BeZero())
+
+			By("lowering vpaCapacityThresholdPercent")
+			resource.Spec.Behavior.VPACapacityThresholdPercent = 50
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			Expect(resource.Spec.Behavior.EffectiveVPAToHPAThresholdPercent()).To(Equal(int32(50)))
+			Expect(resource.Spec.Behavior.EffectiveHPAToVPAThresholdPercent()).To(Equal(int32(50)))
+
+			By("setting only vpaToHpaThresholdPercent")
+			resource.Spec.Behavior.VPAToHPAThresholdPercent = 70
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			Expect(resource.Spec.Behavior.EffectiveVPAToHPAThresholdPercent()).To(Equal(int32(70)))
+			Expect(resource.Spec.Behavior.EffectiveHPAToVPAThresholdPercent()).To(Equal(int32(50)))
+		})
+	})
 })
@@ -0,0 +1,126 @@
+package v1alpha1
+
+import (
+	"slices"
+
+	hpav2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+)
+
+// defaultVPAControlledResources are the resources a VPA controls if its resource policy does not restrict them.
+var defaultVPAControlledResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// VPAControlledResources returns the resources whose requests the VPA controls for at least one container.
+func (r *CranePodAutoscaler) VPAControlledResources() []corev1.ResourceName {
+	policy := r.Spec.VPA.ResourcePolicy
+	if policy == nil || len(policy.ContainerPolicies) == 0 {
+		return slices.Clone(defaultVPAControlledResources)
+	}
+	var resources []corev1.ResourceName
+	for _, containerPolicy := range policy.ContainerPolicies {
+		if containerPolicy.Mode != nil && *containerPolicy.Mode == vpav1.ContainerScalingModeOff {
+			continue
+		}
+		controlled := defaultVPAControlledResources
+		if containerPolicy.ControlledResources != nil {
+			controlled = *containerPolicy.ControlledResources
+		}
+		for _, resource := range controlled {
+			if !slices.Contains(resources, resource) {
+				resources = append(resources, resource)
+			}
+		}
+	}
+	return resources
+}
+
+// HPAMetricResources returns the resources the HPA metrics use, regardless of their target type.
+func (r *CranePodAutoscaler) HPAMetricResources() []corev1.ResourceName {
+	return r.hpaResources(func(hpav2.MetricTarget) bool { return true })
+}
+
+// HPAUtilizationResources returns the resources the HPA scales on by their utilization, i.e. relative to the requests.
+func (r *CranePodAutoscaler) HPAUtilizationResources() []corev1.ResourceName {
+	return r.hpaResources(func(target hpav2.MetricTarget) bool { return target.Type == hpav2.UtilizationMetricType })
+}
+
+// hpaResources returns the resources of the HPA resource metrics whose target matches.
+// An HPA without metrics scales on CPU utilization.
+func (r *CranePodAutoscaler) hpaResources(matches func(hpav2.MetricTarget) bool) []corev1.ResourceName {
+	if len(r.Spec.HPA.Metrics) == 0 {
+		if matches(hpav2.MetricTarget{Type: hpav2.UtilizationMetricType}) {
+			return []corev1.ResourceName{corev1.ResourceCPU}
+		}
+		return nil
+	}
+	var resources []corev1.ResourceName
+	for _, metric := range r.Spec.HPA.Metrics {
+		var resource corev1.ResourceName
+		var target hpav2.MetricTarget
+		switch {
+		case metric.Type == hpav2.ResourceMetricSourceType && metric.Resource != nil:
+			resource, target = metric.Resource.Name, metric.Resource.Target
+		case metric.Type == hpav2.ContainerResourceMetricSourceType && metric.ContainerResource != nil:
+			resource, target = metric.ContainerResource.Name, metric.ContainerResource.Target
+		default:
+			continue
+		}
+		if matches(target) && !slices.Contains(resources, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// UtilizationResourcesControlledByVPA returns the resources the HPA scales on by their utilization
+// whose requests are also controlled by the VPA. Changing their requests changes the utilization the HPA observes.
+func (r *CranePodAutoscaler) UtilizationResourcesControlledByVPA() []corev1.ResourceName {
+	controlled := r.VPAControlledResources()
+	var resources []corev1.ResourceName
+	for _, resource := range r.HPAUtilizationResources() {
+		if slices.Contains(controlled, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// SplitVPAControlledResources returns the resources the VPA controls in Split mode,
+// i.e. the resources it would control otherwise that the HPA metrics do not use.
+func (r *CranePodAutoscaler) SplitVPAControlledResources() []corev1.ResourceName {
+	hpaResources := r.HPAMetricResources()
+	return slices.DeleteFunc(r.VPAControlledResources(), func(resource corev1.ResourceName) bool {
+		return slices.Contains(hpaResources, resource)
+	})
+}
+
+// restrictToSplitResources restricts the resources every container policy of the VPA controls to those
+// the HPA metrics do not use. Containers without a policy of their own are restricted by a policy for all containers.
+func (r *CranePodAutoscaler) restrictToSplitResources(vpaSpec *vpav1.VerticalPodAutoscalerSpec) {
+	hpaResources := r.HPAMetricResources()
+	if vpaSpec.ResourcePolicy == nil {
+		vpaSpec.ResourcePolicy = &vpav1.PodResourcePolicy{}
+	}
+	policies := vpaSpec.ResourcePolicy.ContainerPolicies
+	if !slices.ContainsFunc(policies, func(policy vpav1.ContainerResourcePolicy) bool {
+		return policy.ContainerName == vpav1.DefaultContainerResourcePolicy
+	}) {
+		policies = append(policies, vpav1.ContainerResourcePolicy{ContainerName: vpav1.DefaultContainerResourcePolicy})
+	}
+	for i := range policies {
+		policy := &policies[i]
+		if policy.Mode != nil && *policy.Mode == vpav1.ContainerScalingModeOff {
+			continue
+		}
+		controlled := defaultVPAControlledResources
+		if policy.ControlledResources != nil {
+			controlled = *policy.ControlledResources
+		}
+		restricted := slices.DeleteFunc(slices.Clone(controlled), func(resource corev1.ResourceName) bool {
+			return slices.Contains(hpaResources, resource)
+		})
+		policy.ControlledResources = &restricted
+	}
+	vpaSpec.ResourcePolicy.ContainerPolicies = policies
+}
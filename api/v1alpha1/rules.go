@@ -0,0 +1,143 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	"k8s.io/utils/lru"
+)
+
+// ruleCostLimit limits the cost of evaluating a single switching rule.
+const ruleCostLimit = 100000
+
+// ruleProgramCacheSize is the number of compiled switching rules kept for reuse.
+const ruleProgramCacheSize = 1024
+
+// rulePrograms caches the compiled switching rules by expression, as the rules of every cranepodautoscaler
+// are validated and evaluated on each reconcile, while compiling and type-checking them is expensive.
+var rulePrograms = lru.New(ruleProgramCacheSize)
+
+// RuleHPA is the state of the HPA available to switching rules as variable hpa.
+// +kubebuilder:object:generate=false
+type RuleHPA struct {
+	CurrentReplicas int64 `cel:"currentReplicas"`
+	DesiredReplicas int64 `cel:"desiredReplicas"`
+	MinReplicas     int64 `cel:"minReplicas"`
+	MaxReplicas     int64 `cel:"maxReplicas"`
+}
+
+// RuleVPA is the state of the VPA available to switching rules as variable vpa.
+// +kubebuilder:object:generate=false
+type RuleVPA struct {
+	Utilizations []RuleUtilization `cel:"utilizations"`
+}
+
+// RuleUtilization is the utilization of a container resource recommended by the VPA,
+// i.e. the ratio of its target to its upper bound.
+// +kubebuilder:object:generate=false
+type RuleUtilization struct {
+	// Container is empty if the utilizations of all containers are averaged.
+	Container   string  `cel:"container"`
+	Resource    string  `cel:"resource"`
+	Utilization float64 `cel:"utilization"`
+}
+
+// RuleTarget is the state of the target available to switching rules as variable target.
+// +kubebuilder:object:generate=false
+type RuleTarget struct {
+	Replicas        int64 `cel:"replicas"`
+	CurrentReplicas int64 `cel:"currentReplicas"`
+}
+
+// RuleVariables are the variables switching rules are evaluated with.
+// +kubebuilder:object:generate=false
+type RuleVariables struct {
+	HPA    RuleHPA
+	VPA    RuleVPA
+	Target RuleTarget
+}
+
+// ruleEnv is the CEL environment of the switching rules. It is created once, as creating it is expensive.
+var ruleEnv = sync.OnceValues(func() (*cel.Env, error) {
+	vpaType := cel.ObjectType(ruleTypeName(reflect.TypeFor[RuleVPA]()))
+	return cel.NewEnv(
+		ext.NativeTypes(reflect.TypeFor[RuleHPA](), reflect.TypeFor[RuleVPA](), reflect.TypeFor[RuleTarget](), ext.ParseStructTags(true)),
+		cel.Variable("hpa", cel.ObjectType(ruleTypeName(reflect.TypeFor[RuleHPA]()))),
+		cel.Variable("vpa", vpaType),
+		cel.Variable("target", cel.ObjectType(ruleTypeName(reflect.TypeFor[RuleTarget]()))),
+		cel.Function("maxUtilization",
+			cel.MemberOverload("vpa_max_utilization_string", []*cel.Type{vpaType, cel.StringType}, cel.DoubleType,
+				cel.BinaryBinding(maxUtilization))),
+	)
+})
+
+// ruleTypeName returns the CEL type name of a native type of the rule environment.
+func ruleTypeName(t reflect.Type) string {
+	return fmt.Sprintf("v1alpha1.%s", t.Name())
+}
+
+// maxUtilization implements vpa.maxUtilization(resource), the highest utilization of the resource across all containers.
+func maxUtilization(vpa ref.Val, resource ref.Val) ref.Val {
+	native, err := vpa.ConvertToNative(reflect.TypeFor[*RuleVPA]())
+	if err != nil {
+		return types.WrapErr(err)
+	}
+	name, ok := resource.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(resource)
+	}
+	var highest float64
+	for _, utilization := range native.(*RuleVPA).Utilizations {
+		if utilization.Resource == string(name) {
+			highest = max(highest, utilization.Utilization)
+		}
+	}
+	return types.Double(highest)
+}
+
+// CompileRule compiles and type-checks the expression of a switching rule, which must return a bool.
+// Compiled rules are cached by expression.
+func CompileRule(expression string) (cel.Program, error) {
+	if program, ok := rulePrograms.Get(expression); ok {
+		return program.(cel.Program), nil
+	}
+	env, err := ruleEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("expression must return a bool, not %s", ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(ruleCostLimit))
+	if err != nil {
+		return nil, err
+	}
+	rulePrograms.Add(expression, program)
+	return program, nil
+}
+
+// EvaluateRule evaluates a compiled switching rule with the given variables.
+func EvaluateRule(program cel.Program, variables *RuleVariables) (bool, error) {
+	result, _, err := program.Eval(map[string]any{
+		"hpa":    &variables.HPA,
+		"vpa":    &variables.VPA,
+		"target": &variables.Target,
+	})
+	if err != nil {
+		return false, err
+	}
+	matched, ok := result.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %v instead of a bool", result)
+	}
+	return matched, nil
+}
@@ -2,9 +2,14 @@ package v1alpha1
 
 import (
 	"fmt"
+	"path"
+	"slices"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	hpav2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 )
 
@@ -21,6 +26,245 @@ func (r *CranePodAutoscaler) Validate() error {
 	if r.Spec.Behavior.VPACapacityThresholdPercent < 0 || r.Spec.Behavior.VPACapacityThresholdPercent > 100 {
 		return fmt.Errorf("spec.Behavior.vpaCapacityThresholdPercent must be between 0 and 100")
 	}
+	if r.Spec.Behavior.VPAToHPAThresholdPercent < 0 || r.Spec.Behavior.VPAToHPAThresholdPercent > 100 {
+		return fmt.Errorf("spec.Behavior.vpaToHpaThresholdPercent must be between 0 and 100")
+	}
+	if r.Spec.Behavior.HPAToVPAThresholdPercent < 0 || r.Spec.Behavior.HPAToVPAThresholdPercent > 100 {
+		return fmt.Errorf("spec.Behavior.hpaToVpaThresholdPercent must be between 0 and 100")
+	}
+	vpaToHPA := r.Spec.Behavior.EffectiveVPAToHPAThresholdPercent()
+	hpaToVPA := r.Spec.Behavior.EffectiveHPAToVPAThresholdPercent()
+	if hpaToVPA > vpaToHPA {
+		return fmt.Errorf("spec.Behavior.hpaToVpaThresholdPercent (%d) must not be greater than spec.Behavior.vpaToHpaThresholdPercent (%d)", hpaToVPA, vpaToHPA)
+	}
+	if err := validateResourceThresholds(&r.Spec.Behavior); err != nil {
+		return err
+	}
+	if err := validateContainerSelection(r.Spec.Behavior.Containers); err != nil {
+		return err
+	}
+	switch r.Spec.Behavior.PassiveVPAUpdateMode {
+	case "", PassiveVPAUpdateModeOff, PassiveVPAUpdateModeInitial:
+	default:
+		return fmt.Errorf("spec.Behavior.passiveVpaUpdateMode must be one of %q or %q", PassiveVPAUpdateModeOff, PassiveVPAUpdateModeInitial)
+	}
+	if err := validateRules(&r.Spec.Behavior); err != nil {
+		return err
+	}
+	if err := validateHPABaseline(r.Spec.Behavior.HPABaseline); err != nil {
+		return err
+	}
+	if r.Spec.Behavior.SwitchToHPA.GetHandoff() != nil {
+		return fmt.Errorf("spec.Behavior.switchToHPA.handoff is not supported, a handoff is only performed when switching to VPA")
+	}
+	if r.Spec.Behavior.SwitchToHPA != nil && r.Spec.Behavior.SwitchToHPA.HPAMetricThresholdPercent != nil {
+		return fmt.Errorf("spec.Behavior.switchToHPA.hpaMetricThresholdPercent is not supported, the HPA metrics only hold back the switch to VPA")
+	}
+	if r.Spec.Mode == ModeSplit {
+		if err := r.validateSplit(); err != nil {
+			return err
+		}
+	}
+	if err := validateDeletionPolicy(&r.Spec); err != nil {
+		return err
+	}
+	if err := validateAutoscalerTemplate("spec.hpaTemplate", r.Spec.HPATemplate, r.HPAName()); err != nil {
+		return err
+	}
+	if err := validateAutoscalerTemplate("spec.vpaTemplate", r.Spec.VPATemplate, r.VPAName()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateAutoscalerTemplate(field string, template *AutoscalerTemplate, name string) error {
+	if template == nil {
+		return nil
+	}
+	if template.Metadata.NameSuffix != "" {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("%s.metadata.nameSuffix results in the invalid name %q: %s", field, name, strings.Join(errs, ", "))
+		}
+	}
+	for key, value := range template.Metadata.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("%s.metadata.labels: invalid key %q: %s", field, key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("%s.metadata.labels: invalid value %q of key %q: %s", field, value, key, strings.Join(errs, ", "))
+		}
+	}
+	for key := range template.Metadata.Annotations {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("%s.metadata.annotations: invalid key %q: %s", field, key, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// Warnings returns warnings about settings that are valid, but do not take effect as configured.
+func (r *CranePodAutoscaler) Warnings() []string {
+	var warnings []string
+	if r.Spec.Behavior.PassiveVPAUpdateMode == PassiveVPAUpdateModeInitial {
+		if resources := r.UtilizationResourcesControlledByVPA(); len(resources) > 0 {
+			warnings = append(warnings, fmt.Sprintf("spec.Behavior.passiveVpaUpdateMode %s falls back to %s, because the HPA scales on the utilization of %v which the VPA controls",
+				PassiveVPAUpdateModeInitial, PassiveVPAUpdateModeOff, resources))
+		}
+	}
+	if handoff := r.Spec.Behavior.SwitchToVPA.GetHandoff(); handoff != nil && handoff.UpdatedPodsPercent != nil && *handoff.UpdatedPodsPercent > 0 {
+		if policy := r.Spec.VPA.UpdatePolicy; policy != nil && policy.UpdateMode != nil &&
+			(*policy.UpdateMode == vpav1.UpdateModeOff || *policy.UpdateMode == vpav1.UpdateModeInitial) {
+			warnings = append(warnings, fmt.Sprintf("spec.Behavior.switchToVPA.handoff.updatedPodsPercent may wait for the handoff timeout, because the VPA update mode %s does not update running pods",
+				*policy.UpdateMode))
+		}
+	}
+	if len(r.Spec.Behavior.Rules) > 0 && (r.Spec.Behavior.Strategy == "" || r.Spec.Behavior.Strategy == DecisionStrategyThreshold) {
+		warnings = append(warnings, fmt.Sprintf("spec.Behavior.rules are ignored, because spec.Behavior.strategy is not %s", DecisionStrategyRules))
+	}
+	return warnings
+}
+
+// validateSplit makes sure that the VPA does not control resources the HPA metrics use in Split mode,
+// as changing their requests would change what the HPA observes.
+func (r *CranePodAutoscaler) validateSplit() error {
+	hpaResources := r.HPAMetricResources()
+	if policy := r.Spec.VPA.ResourcePolicy; policy != nil {
+		for i, containerPolicy := range policy.ContainerPolicies {
+			if containerPolicy.ControlledResources == nil || (containerPolicy.Mode != nil && *containerPolicy.Mode == vpav1.ContainerScalingModeOff) {
+				continue
+			}
+			for _, resource := range *containerPolicy.ControlledResources {
+				if slices.Contains(hpaResources, resource) {
+					return fmt.Errorf("spec.VPA.resourcePolicy.containerPolicies[%d].controlledResources contains %q, which the HPA metrics use in mode %s", i, resource, ModeSplit)
+				}
+			}
+		}
+	}
+	if len(r.SplitVPAControlledResources()) == 0 {
+		return fmt.Errorf("spec.mode %s leaves no resource for the VPA to control, because the HPA metrics use %v", ModeSplit, hpaResources)
+	}
+	return nil
+}
+
+func validateDeletionPolicy(spec *CranePodAutoscalerSpec) error {
+	switch spec.DeletionPolicy {
+	case "", DeletionPolicyDelete, DeletionPolicyOrphanActiveHPA:
+		if spec.RestoreReplicas != nil {
+			return fmt.Errorf("spec.restoreReplicas may only be set if spec.deletionPolicy is %s", DeletionPolicyRestoreReplicas)
+		}
+	case DeletionPolicyRestoreReplicas:
+		if spec.RestoreReplicas == nil {
+			return fmt.Errorf("spec.restoreReplicas must be set if spec.deletionPolicy is %s", DeletionPolicyRestoreReplicas)
+		}
+		if *spec.RestoreReplicas < 0 {
+			return fmt.Errorf("spec.restoreReplicas must not be negative")
+		}
+	default:
+		return fmt.Errorf("spec.deletionPolicy %q is not supported", spec.DeletionPolicy)
+	}
+	return nil
+}
+
+func validateRules(behavior *CranePodAutoscalerBehavior) error {
+	if behavior.Strategy == DecisionStrategyRules && len(behavior.Rules) == 0 {
+		return fmt.Errorf("spec.Behavior.rules must be set if strategy is %s", DecisionStrategyRules)
+	}
+	for i, rule := range behavior.Rules {
+		if rule.SwitchTo != "HPA" && rule.SwitchTo != "VPA" {
+			return fmt.Errorf("spec.Behavior.rules[%d].switchTo must be one of %q or %q", i, "HPA", "VPA")
+		}
+		if _, err := CompileRule(rule.Expression); err != nil {
+			return fmt.Errorf("spec.Behavior.rules[%d].expression is invalid: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateHPABaseline(baseline *HPABaseline) error {
+	if baseline == nil {
+		return nil
+	}
+	switch baseline.Source {
+	case HPABaselineSourceTarget, HPABaselineSourceUpperBound:
+		if len(baseline.Containers) > 0 {
+			return fmt.Errorf("spec.Behavior.hpaBaseline.containers may only be set if source is %s", HPABaselineSourceExplicit)
+		}
+	case HPABaselineSourceExplicit:
+		if len(baseline.Containers) == 0 {
+			return fmt.Errorf("spec.Behavior.hpaBaseline.containers must be set if source is %s", HPABaselineSourceExplicit)
+		}
+	default:
+		return fmt.Errorf("spec.Behavior.hpaBaseline.source %q is not supported", baseline.Source)
+	}
+	seen := map[string]bool{}
+	for i, container := range baseline.Containers {
+		if seen[container.ContainerName] {
+			return fmt.Errorf("spec.Behavior.hpaBaseline.containers[%d] is a duplicate for container %q", i, container.ContainerName)
+		}
+		seen[container.ContainerName] = true
+		if len(container.Requests) == 0 {
+			return fmt.Errorf("spec.Behavior.hpaBaseline.containers[%d].requests must not be empty", i)
+		}
+	}
+	return nil
+}
+
+func validateContainerSelection(selection *ContainerSelection) error {
+	if selection == nil {
+		return nil
+	}
+	for _, pattern := range append(append([]string{}, selection.Include...), selection.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("spec.Behavior.containers: invalid container name pattern %q: %w", pattern, err)
+		}
+	}
+	switch selection.AggregationOrDefault() {
+	case ContainerAggregationMax, ContainerAggregationWeightedAverage:
+		if selection.MainContainer != "" {
+			return fmt.Errorf("spec.Behavior.containers.mainContainer may only be set if aggregation is %s", ContainerAggregationMainContainer)
+		}
+	case ContainerAggregationMainContainer:
+		if selection.MainContainer == "" {
+			return fmt.Errorf("spec.Behavior.containers.mainContainer must be set if aggregation is %s", ContainerAggregationMainContainer)
+		}
+	default:
+		return fmt.Errorf("spec.Behavior.containers.aggregation %q is not supported", selection.Aggregation)
+	}
+	positiveWeight := len(selection.Weights) == 0
+	for i, w := range selection.Weights {
+		if w.Weight < 0 {
+			return fmt.Errorf("spec.Behavior.containers.weights[%d].weight must not be negative", i)
+		}
+		positiveWeight = positiveWeight || w.Weight > 0
+	}
+	if !positiveWeight {
+		return fmt.Errorf("spec.Behavior.containers.weights must contain at least one positive weight")
+	}
+	return nil
+}
+
+func validateResourceThresholds(behavior *CranePodAutoscalerBehavior) error {
+	seen := map[string]bool{}
+	for i, t := range behavior.ResourceThresholds {
+		if t.Resource != corev1.ResourceCPU && t.Resource != corev1.ResourceMemory {
+			return fmt.Errorf("spec.Behavior.resourceThresholds[%d].resource must be one of %q or %q", i, corev1.ResourceCPU, corev1.ResourceMemory)
+		}
+		key := t.ContainerName + "/" + string(t.Resource)
+		if seen[key] {
+			return fmt.Errorf("spec.Behavior.resourceThresholds[%d] is a duplicate for resource %q and container %q", i, t.Resource, t.ContainerName)
+		}
+		seen[key] = true
+		if t.VPAToHPAThresholdPercent < 0 || t.VPAToHPAThresholdPercent > 100 {
+			return fmt.Errorf("spec.Behavior.resourceThresholds[%d].vpaToHpaThresholdPercent must be between 0 and 100", i)
+		}
+		if t.HPAToVPAThresholdPercent < 0 || t.HPAToVPAThresholdPercent > 100 {
+			return fmt.Errorf("spec.Behavior.resourceThresholds[%d].hpaToVpaThresholdPercent must be between 0 and 100", i)
+		}
+		vpaToHPA, hpaToVPA := behavior.ThresholdPercentsFor(t.ContainerName, t.Resource)
+		if hpaToVPA > vpaToHPA {
+			return fmt.Errorf("spec.Behavior.resourceThresholds[%d]: hpaToVpaThresholdPercent (%d) must not be greater than vpaToHpaThresholdPercent (%d)", i, hpaToVPA, vpaToHPA)
+		}
+	}
 	return nil
 }
 
@@ -21,10 +21,139 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalerTemplate) DeepCopyInto(out *AutoscalerTemplate) {
+	*out = *in
+	in.Metadata.DeepCopyInto(&out.Metadata)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalerTemplate.
+func (in *AutoscalerTemplate) DeepCopy() *AutoscalerTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalerTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalerTemplateMetadata) DeepCopyInto(out *AutoscalerTemplateMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalerTemplateMetadata.
+func (in *AutoscalerTemplateMetadata) DeepCopy() *AutoscalerTemplateMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalerTemplateMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalerTransition) DeepCopyInto(out *AutoscalerTransition) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalerTransition.
+func (in *AutoscalerTransition) DeepCopy() *AutoscalerTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalerTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerRequests) DeepCopyInto(out *ContainerRequests) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRequests.
+func (in *ContainerRequests) DeepCopy() *ContainerRequests {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerRequests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerSelection) DeepCopyInto(out *ContainerSelection) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Weights != nil {
+		in, out := &in.Weights, &out.Weights
+		*out = make([]ContainerWeight, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerSelection.
+func (in *ContainerSelection) DeepCopy() *ContainerSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerWeight) DeepCopyInto(out *ContainerWeight) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerWeight.
+func (in *ContainerWeight) DeepCopy() *ContainerWeight {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerWeight)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CranePodAutoscaler) DeepCopyInto(out *CranePodAutoscaler) {
 	*out = *in
@@ -55,6 +184,41 @@ func (in *CranePodAutoscaler) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CranePodAutoscalerBehavior) DeepCopyInto(out *CranePodAutoscalerBehavior) {
 	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]SwitchingRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceThresholds != nil {
+		in, out := &in.ResourceThresholds, &out.ResourceThresholds
+		*out = make([]ResourceThreshold, len(*in))
+		copy(*out, *in)
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = new(ContainerSelection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SwitchToHPA != nil {
+		in, out := &in.SwitchToHPA, &out.SwitchToHPA
+		*out = new(ModeSwitchRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SwitchToVPA != nil {
+		in, out := &in.SwitchToVPA, &out.SwitchToVPA
+		*out = new(ModeSwitchRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EvaluationIntervalSeconds != nil {
+		in, out := &in.EvaluationIntervalSeconds, &out.EvaluationIntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HPABaseline != nil {
+		in, out := &in.HPABaseline, &out.HPABaseline
+		*out = new(HPABaseline)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CranePodAutoscalerBehavior.
@@ -104,7 +268,22 @@ func (in *CranePodAutoscalerSpec) DeepCopyInto(out *CranePodAutoscalerSpec) {
 	*out = *in
 	in.HPA.DeepCopyInto(&out.HPA)
 	in.VPA.DeepCopyInto(&out.VPA)
-	out.Behavior = in.Behavior
+	in.Behavior.DeepCopyInto(&out.Behavior)
+	if in.RestoreReplicas != nil {
+		in, out := &in.RestoreReplicas, &out.RestoreReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HPATemplate != nil {
+		in, out := &in.HPATemplate, &out.HPATemplate
+		*out = new(AutoscalerTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VPATemplate != nil {
+		in, out := &in.VPATemplate, &out.VPATemplate
+		*out = new(AutoscalerTemplate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CranePodAutoscalerSpec.
@@ -127,6 +306,52 @@ func (in *CranePodAutoscalerStatus) DeepCopyInto(out *CranePodAutoscalerStatus)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CurrentUtilizationPercent != nil {
+		in, out := &in.CurrentUtilizationPercent, &out.CurrentUtilizationPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ThresholdPercent != nil {
+		in, out := &in.ThresholdPercent, &out.ThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HPAMetrics != nil {
+		in, out := &in.HPAMetrics, &out.HPAMetrics
+		*out = make([]HPAMetricStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Transitions != nil {
+		in, out := &in.Transitions, &out.Transitions
+		*out = make([]AutoscalerTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PendingSwitch != nil {
+		in, out := &in.PendingSwitch, &out.PendingSwitch
+		*out = new(PendingModeSwitch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Override != nil {
+		in, out := &in.Override, &out.Override
+		*out = new(ModeOverride)
+		**out = **in
+	}
+	if in.Handoff != nil {
+		in, out := &in.Handoff, &out.Handoff
+		*out = new(HandoffStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HPABaseline != nil {
+		in, out := &in.HPABaseline, &out.HPABaseline
+		*out = new(HPABaselineStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CranePodAutoscalerStatus.
@@ -138,3 +363,214 @@ func (in *CranePodAutoscalerStatus) DeepCopy() *CranePodAutoscalerStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HPABaseline) DeepCopyInto(out *HPABaseline) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerRequests, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HPABaseline.
+func (in *HPABaseline) DeepCopy() *HPABaseline {
+	if in == nil {
+		return nil
+	}
+	out := new(HPABaseline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HPABaselineStatus) DeepCopyInto(out *HPABaselineStatus) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerRequests, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HPABaselineStatus.
+func (in *HPABaselineStatus) DeepCopy() *HPABaselineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HPABaselineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HPAMetricStatus) DeepCopyInto(out *HPAMetricStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HPAMetricStatus.
+func (in *HPAMetricStatus) DeepCopy() *HPAMetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HPAMetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HandoffRules) DeepCopyInto(out *HandoffRules) {
+	*out = *in
+	if in.MaxReplicasStep != nil {
+		in, out := &in.MaxReplicasStep, &out.MaxReplicasStep
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StepIntervalSeconds != nil {
+		in, out := &in.StepIntervalSeconds, &out.StepIntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UpdatedPodsPercent != nil {
+		in, out := &in.UpdatedPodsPercent, &out.UpdatedPodsPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HandoffRules.
+func (in *HandoffRules) DeepCopy() *HandoffRules {
+	if in == nil {
+		return nil
+	}
+	out := new(HandoffRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HandoffStatus) DeepCopyInto(out *HandoffStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.LastStepTime != nil {
+		in, out := &in.LastStepTime, &out.LastStepTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HandoffStatus.
+func (in *HandoffStatus) DeepCopy() *HandoffStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HandoffStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModeOverride) DeepCopyInto(out *ModeOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModeOverride.
+func (in *ModeOverride) DeepCopy() *ModeOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ModeOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModeSwitchRules) DeepCopyInto(out *ModeSwitchRules) {
+	*out = *in
+	if in.StabilizationWindowSeconds != nil {
+		in, out := &in.StabilizationWindowSeconds, &out.StabilizationWindowSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Handoff != nil {
+		in, out := &in.Handoff, &out.Handoff
+		*out = new(HandoffRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HPAMetricThresholdPercent != nil {
+		in, out := &in.HPAMetricThresholdPercent, &out.HPAMetricThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModeSwitchRules.
+func (in *ModeSwitchRules) DeepCopy() *ModeSwitchRules {
+	if in == nil {
+		return nil
+	}
+	out := new(ModeSwitchRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingModeSwitch) DeepCopyInto(out *PendingModeSwitch) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingModeSwitch.
+func (in *PendingModeSwitch) DeepCopy() *PendingModeSwitch {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingModeSwitch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceThreshold) DeepCopyInto(out *ResourceThreshold) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceThreshold.
+func (in *ResourceThreshold) DeepCopy() *ResourceThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwitchingRule) DeepCopyInto(out *SwitchingRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwitchingRule.
+func (in *SwitchingRule) DeepCopy() *SwitchingRule {
+	if in == nil {
+		return nil
+	}
+	out := new(SwitchingRule)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -20,12 +20,14 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -55,11 +57,15 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var defaultEvaluationInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true, "Serve metrics endpoint securely via HTTPS.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false, "Enable HTTP/2 for the metrics and webhook servers.")
+	flag.DurationVar(&defaultEvaluationInterval, "default-evaluation-interval", 5*time.Minute,
+		"Interval after which scaling decisions are re-evaluated unless a CranePodAutoscaler sets "+
+			"behavior.evaluationIntervalSeconds. 0 disables the periodic re-evaluation.")
 	opts := zap.Options{Development: true}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
@@ -95,9 +101,26 @@ func main() {
 		os.Exit(1)
 	}
 
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	inPlacePodResize, err := controller.InPlacePodResizeAvailable(discoveryClient)
+	if err != nil {
+		// Recreate works on every cluster, so the controller starts anyway.
+		setupLog.Error(err, "unable to detect in-place pod resize, falling back to Recreate")
+	}
+	setupLog.Info("detected in-place pod resize", "available", inPlacePodResize)
+
 	if err = (&controller.CranePodAutoscalerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Scheme:    mgr.GetScheme(),
+		// The core events API is what "kubectl describe" shows, hence the legacy recorder.
+		Recorder:                  mgr.GetEventRecorderFor("cranepodautoscaler-controller"), //nolint:staticcheck
+		DefaultEvaluationInterval: defaultEvaluationInterval,
+		InPlacePodResize:          inPlacePodResize,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CranePodAutoscaler")
 		os.Exit(1)
@@ -0,0 +1,99 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	hpav2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// Reasons of the Conflict condition
+const (
+	conflictReasonNone                     = "NoConflict"
+	conflictReasonOwnedByAnotherController = "OwnedByAnotherController"
+	conflictReasonNotAdopted               = "NotAdopted"
+	conflictReasonTargetMismatch           = "TargetMismatch"
+	conflictReasonFieldManagers            = "ChangedByOtherFieldManagers"
+)
+
+// ownershipConflictError describes why an existing HPA or VPA cannot be managed by a cranepodautoscaler.
+type ownershipConflictError struct {
+	reason  string
+	message string
+}
+
+func (e *ownershipConflictError) Error() string {
+	return e.message
+}
+
+// claimAutoscaler makes sure an existing HPA or VPA is controlled by the cranepodautoscaler.
+// Objects without a controller are adopted if spec.adoptExisting is set and they target the same workload.
+// An *ownershipConflictError is returned for objects that must not be taken over.
+func (r *CranePodAutoscalerReconciler) claimAutoscaler(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, resourceKind string, obj client.Object, sameTarget bool) error {
+	if metav1.IsControlledBy(obj, craneAutoscaler) {
+		return nil
+	}
+	if owner := metav1.GetControllerOf(obj); owner != nil {
+		return &ownershipConflictError{reason: conflictReasonOwnedByAnotherController,
+			message: fmt.Sprintf("%s %s is controlled by %s %s", resourceKind, obj.GetName(), owner.Kind, owner.Name)}
+	}
+	if !craneAutoscaler.Spec.AdoptExisting {
+		return &ownershipConflictError{reason: conflictReasonNotAdopted,
+			message: fmt.Sprintf("%s %s already exists and is not managed by this cranepodautoscaler, set spec.adoptExisting to adopt it", resourceKind, obj.GetName())}
+	}
+	if !sameTarget {
+		return &ownershipConflictError{reason: conflictReasonTargetMismatch,
+			message: fmt.Sprintf("%s %s already exists, but targets a different workload", resourceKind, obj.GetName())}
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("Adopting existing resource", "resource.Kind", resourceKind,
+		"resource.Namespace", obj.GetNamespace(), "resource.Name", obj.GetName())
+	if err := ctrl.SetControllerReference(craneAutoscaler, obj, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Update(ctx, obj); err != nil {
+		logger.Error(err, "Failed to adopt resource", "resource.Kind", resourceKind,
+			"resource.Namespace", obj.GetNamespace(), "resource.Name", obj.GetName())
+		recordReconcileError(reconcilePhaseUpdate)
+		r.recordUpdateConflict(craneAutoscaler, err, resourceKind+" "+obj.GetName())
+		return err
+	}
+	r.event(craneAutoscaler, corev1.EventTypeNormal, eventReasonAdopted, "Adopted existing %s %s", resourceKind, obj.GetName())
+	return nil
+}
+
+// hpaHasSameTarget returns whether the HPA scales the workload targeted by the cranepodautoscaler.
+func hpaHasSameTarget(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, hpa *hpav2.HorizontalPodAutoscaler) bool {
+	return hpa.Spec.ScaleTargetRef == craneAutoscaler.Spec.HPA.ScaleTargetRef
+}
+
+// vpaHasSameTarget returns whether the VPA scales the workload targeted by the cranepodautoscaler.
+func vpaHasSameTarget(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, vpa *vpav1.VerticalPodAutoscaler) bool {
+	return vpa.Spec.TargetRef != nil && craneAutoscaler.Spec.VPA.TargetRef != nil &&
+		*vpa.Spec.TargetRef == *craneAutoscaler.Spec.VPA.TargetRef
+}
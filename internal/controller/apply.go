@@ -0,0 +1,160 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// fieldManager is the field manager the HPA and VPA are applied with.
+const fieldManager = "crane-autoscaler"
+
+// eventReasonOwnershipConflict is emitted when the HPA or VPA was created concurrently by other field managers.
+const eventReasonOwnershipConflict = "OwnershipConflict"
+
+// legacyFieldManager is the field manager of the HPA and VPA fields written with Create and Update by earlier versions
+// of the controller. The API server derives it from the user agent, which starts with the name of the manager binary.
+var legacyFieldManager = strings.SplitN(rest.DefaultKubernetesUserAgent(), "/", 2)[0]
+
+// applyAutoscaler applies the desired HPA or VPA with server-side apply, so that the controller only owns the fields
+// it sets and fields defaulted by the API server or set by others do not show up as a difference.
+// Fields the controller owns are never taken over from other field managers: if they changed such a field,
+// an *ownershipConflictError is returned and the object is left untouched until the change is reverted.
+// The applied object is updated with the response of the API server.
+func (r *CranePodAutoscalerReconciler) applyAutoscaler(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, resourceKind string, obj client.Object) error {
+	if err := r.migrateLegacyFieldManager(ctx, craneAutoscaler, resourceKind, obj); err != nil {
+		return err
+	}
+	return r.apply(ctx, resourceKind, obj)
+}
+
+// createAutoscaler creates the HPA or VPA with server-side apply after it was found missing. Another writer may create
+// an object of the same name with the same values in the meantime, which the apply shares without failing. The object
+// returned by the API server is therefore checked for fields of other field managers, and such a takeover is reported.
+// It returns whether the object was created by the controller.
+func (r *CranePodAutoscalerReconciler) createAutoscaler(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, resourceKind string, obj client.Object) (bool, error) {
+	if err := r.apply(ctx, resourceKind, obj); err != nil {
+		return false, err
+	}
+	if managers := otherFieldManagers(obj); len(managers) > 0 {
+		log.FromContext(ctx).Info("Took over resource created concurrently by other field managers", "resource.Kind", resourceKind,
+			"resource.Namespace", obj.GetNamespace(), "resource.Name", obj.GetName(), "managers", managers)
+		r.event(craneAutoscaler, corev1.EventTypeWarning, eventReasonOwnershipConflict, "Took over %s %s, which was created concurrently by %v",
+			resourceKind, obj.GetName(), managers)
+		return false, nil
+	}
+	return true, nil
+}
+
+// apply applies the HPA or VPA without forcing ownership. A conflict with other field managers is returned
+// as an *ownershipConflictError.
+func (r *CranePodAutoscalerReconciler) apply(ctx context.Context, resourceKind string, obj client.Object) error {
+	applyConfiguration, err := r.applyConfigurationFor(obj)
+	if err != nil {
+		return err
+	}
+
+	err = r.Apply(ctx, client.ApplyConfigurationFromUnstructured(applyConfiguration), client.FieldOwner(fieldManager))
+	if apierrors.IsConflict(err) {
+		log.FromContext(ctx).Info("Fields of the resource are managed by other field managers", "resource.Kind", resourceKind,
+			"resource.Namespace", obj.GetNamespace(), "resource.Name", obj.GetName(), "conflict", err.Error())
+		return &ownershipConflictError{reason: conflictReasonFieldManagers,
+			message: fmt.Sprintf("%s %s was changed by other field managers, revert their changes to let the controller manage it again: %s",
+				resourceKind, obj.GetName(), err)}
+	}
+	if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(applyConfiguration.Object, obj)
+}
+
+// migrateLegacyFieldManager hands the fields that earlier versions of the controller wrote with Update over to the
+// apply field manager, so that applying them does not conflict with the controller's own earlier writes.
+// Only objects controlled by the cranepodautoscaler are migrated, as other controllers may use the same manager name.
+func (r *CranePodAutoscalerReconciler) migrateLegacyFieldManager(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, resourceKind string, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return err
+	}
+	newObj, err := r.Scheme.New(gvk)
+	if err != nil {
+		return err
+	}
+	current := newObj.(client.Object)
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(current, craneAutoscaler) {
+		return nil
+	}
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(current, sets.New(legacyFieldManager), fieldManager)
+	if err != nil || patch == nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Migrating fields written with Update to server-side apply", "resource.Kind", resourceKind,
+		"resource.Namespace", current.GetNamespace(), "resource.Name", current.GetName(), "manager", legacyFieldManager)
+	return r.Patch(ctx, current, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// otherFieldManagers returns the field managers other than the controller that manage fields of the object
+// itself, leaving out those that only write its status.
+func otherFieldManagers(obj client.Object) []string {
+	var managers []string
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == fieldManager || entry.Subresource != "" || slices.Contains(managers, entry.Manager) {
+			continue
+		}
+		managers = append(managers, entry.Manager)
+	}
+	return managers
+}
+
+// applyConfigurationFor converts a generated HPA or VPA into an apply configuration that only contains
+// the fields the controller sets.
+func (r *CranePodAutoscalerReconciler) applyConfigurationFor(obj client.Object) (*unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	applyConfiguration := &unstructured.Unstructured{Object: content}
+	applyConfiguration.SetGroupVersionKind(gvk)
+	unstructured.RemoveNestedField(applyConfiguration.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(applyConfiguration.Object, "status")
+	return applyConfiguration, nil
+}
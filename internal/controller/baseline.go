@@ -0,0 +1,180 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// eventReasonHPABaselineFrozen is emitted when the HPA baseline is frozen on a switch from VPA to HPA.
+const eventReasonHPABaselineFrozen = "HPABaselineFrozen"
+
+// Reasons of the Progressing condition and the events while the HPA baseline is rolled out
+const (
+	baselineReasonApplying = "ApplyingHPABaseline"
+	baselineReasonApplied  = "HPABaselineApplied"
+	baselineReasonTimedOut = "HPABaselineTimedOut"
+)
+
+// updateHPABaseline freezes the HPA baseline on a switch from VPA to HPA, so that the passive VPA applies it to new pods.
+// The baseline is kept while the HPA stays active and cleared once the VPA is active again or no baseline is configured.
+func (r *CranePodAutoscalerReconciler) updateHPABaseline(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, from string, to string, vpa *vpav1.VerticalPodAutoscaler) {
+	logger := log.FromContext(ctx)
+	baseline := craneAutoscaler.Spec.Behavior.HPABaseline
+	if to != refHPA || baseline == nil {
+		craneAutoscaler.Status.HPABaseline = nil
+		return
+	}
+	if from != refVPA {
+		return
+	}
+
+	containers := craneAutoscaler.HPABaselineFrom(vpa.Status.Recommendation)
+	if len(containers) == 0 {
+		logger.Info("VPA has no recommendation to take the HPA baseline from, keeping the requests applied by the VPA", "source", baseline.Source)
+		craneAutoscaler.Status.HPABaseline = nil
+		return
+	}
+	craneAutoscaler.Status.HPABaseline = &autoscalingv1alpha1.HPABaselineStatus{
+		Source:     baseline.Source,
+		Containers: containers,
+		Time:       metav1.NewTime(r.now()),
+	}
+	summary := summarizeContainerRequests(containers)
+	logger.Info("Froze HPA baseline", "source", baseline.Source, "baseline", summary)
+	r.event(craneAutoscaler, corev1.EventTypeNormal, eventReasonHPABaselineFrozen, "Froze HPA baseline from %s: %s", baseline.Source, summary)
+}
+
+// hpaBaselineTolerance is the relative deviation from the HPA baseline up to which the requests of a pod count as
+// carrying it, so that rounding of the requests does not keep the rollout from completing.
+const hpaBaselineTolerance = 0.05
+
+// trackHPABaselineRollout reports in the Progressing condition how many running pods the VPA updated to the frozen
+// HPA baseline. The HPA is enabled regardless. Once all pods carry the baseline or the timeout passed, the baseline is
+// marked as applied and the VPA only applies it to new pods. The returned duration is the time after which the pods
+// are checked again, zero once the rollout ended.
+func (r *CranePodAutoscalerReconciler) trackHPABaselineRollout(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+	baseline := craneAutoscaler.Status.HPABaseline
+	if baseline == nil || baseline.Applied {
+		return 0, nil
+	}
+	if pinnedAutoscaler(craneAutoscaler.Spec.Mode) == refHPA {
+		// Running pods are not evicted while the HPA is pinned, e.g. during an incident.
+		baseline.Applied = true
+		return 0, nil
+	}
+
+	pods, err := r.countPodsWithin(ctx, craneAutoscaler, baselineRecommendations(baseline.Containers, hpaBaselineTolerance))
+	if err != nil {
+		logger.Error(err, "Failed to count the pods carrying the HPA baseline")
+		recordReconcileError(reconcilePhaseGet)
+		return 0, err
+	}
+	baseline.UpdatedPods = pods.updated
+	baseline.TotalPods = pods.total
+	if pods.updated == pods.total {
+		logger.Info("Pods carry the HPA baseline", "pods", pods.total)
+		baseline.Applied = true
+		meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeProgressingCraneAutoscaler,
+			Status: metav1.ConditionFalse, Reason: baselineReasonApplied, Message: fmt.Sprintf("All %d pods carry the HPA baseline", pods.total)})
+		return 0, nil
+	}
+	if timeout := craneAutoscaler.Spec.Behavior.HPABaseline.Timeout(); r.now().Sub(baseline.Time.Time) >= timeout {
+		logger.Info("HPA baseline was not rolled out in time, only applying it to new pods", "timeout", timeout, "updatedPods", pods.updated, "totalPods", pods.total)
+		r.event(craneAutoscaler, corev1.EventTypeWarning, baselineReasonTimedOut, "Only %d of %d pods carry the HPA baseline after %s, only applying it to new pods", pods.updated, pods.total, timeout)
+		baseline.Applied = true
+		meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeProgressingCraneAutoscaler,
+			Status: metav1.ConditionFalse, Reason: baselineReasonTimedOut, Message: fmt.Sprintf("HPA baseline was not rolled out within %s", timeout)})
+		return 0, nil
+	}
+
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeProgressingCraneAutoscaler,
+		Status: metav1.ConditionTrue, Reason: baselineReasonApplying, Message: fmt.Sprintf("Applying HPA baseline: %s", pods)})
+	return pods.checkInterval(), nil
+}
+
+// baselineRecommendations returns the requests per container as VPA recommendations whose bounds deviate from the
+// requests by the given tolerance.
+func baselineRecommendations(containers []autoscalingv1alpha1.ContainerRequests, tolerance float64) []vpav1.RecommendedContainerResources {
+	recommendations := make([]vpav1.RecommendedContainerResources, 0, len(containers))
+	for _, container := range containers {
+		recommendations = append(recommendations, vpav1.RecommendedContainerResources{
+			ContainerName: container.ContainerName,
+			Target:        container.Requests,
+			LowerBound:    scaleRequests(container.Requests, 1-tolerance),
+			UpperBound:    scaleRequests(container.Requests, 1+tolerance),
+		})
+	}
+	return recommendations
+}
+
+// scaleRequests returns the requests multiplied by the given factor.
+func scaleRequests(requests corev1.ResourceList, factor float64) corev1.ResourceList {
+	scaled := make(corev1.ResourceList, len(requests))
+	for name, quantity := range requests {
+		scaled[name] = *resource.NewMilliQuantity(int64(math.Round(float64(quantity.MilliValue())*factor)), quantity.Format)
+	}
+	return scaled
+}
+
+// uncappedRecommendations replaces the targets of the resources the HPA baseline pins with the uncapped targets.
+// While the baseline is pinned the VPA caps its whole recommendation to it, so the capped target would always report
+// a utilization of 100%. The upper bounds stay at the baseline, so the utilization compares the recommendation
+// to the baseline instead.
+func uncappedRecommendations(baseline *autoscalingv1alpha1.HPABaselineStatus, recommendations []vpav1.RecommendedContainerResources) []vpav1.RecommendedContainerResources {
+	if baseline == nil {
+		return recommendations
+	}
+	uncapped := make([]vpav1.RecommendedContainerResources, len(recommendations))
+	for i := range recommendations {
+		recommendations[i].DeepCopyInto(&uncapped[i])
+		index := slices.IndexFunc(baseline.Containers, func(container autoscalingv1alpha1.ContainerRequests) bool {
+			return container.ContainerName == recommendations[i].ContainerName
+		})
+		if index < 0 {
+			continue
+		}
+		for name := range baseline.Containers[index].Requests {
+			if target, ok := recommendations[i].UncappedTarget[name]; ok {
+				if uncapped[i].Target == nil {
+					uncapped[i].Target = corev1.ResourceList{}
+				}
+				uncapped[i].Target[name] = target.DeepCopy()
+			}
+		}
+	}
+	return uncapped
+}
+
+// summarizeContainerRequests returns a human-readable summary of the requests per container.
+func summarizeContainerRequests(containers []autoscalingv1alpha1.ContainerRequests) string {
+	return summarizeVPATarget(baselineRecommendations(containers, 0))
+}
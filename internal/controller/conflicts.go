@@ -0,0 +1,62 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// conflictReasonAutoscalers is the reason of the Conflict condition if other autoscalers target the same workload.
+const conflictReasonAutoscalers = "ConflictingAutoscalers"
+
+// findConflictingAutoscalers returns the other HPAs, VPAs and cranepodautoscalers that targeted the same workload
+// before the cranepodautoscaler was created. Newer ones are not returned, so that they cannot stop a cranepodautoscaler
+// that already scales the workload. A newer cranepodautoscaler reports the conflict itself.
+func (r *CranePodAutoscalerReconciler) findConflictingAutoscalers(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) ([]autoscalingv1alpha1.ConflictingAutoscaler, error) {
+	conflicts, err := craneAutoscaler.FindConflictingAutoscalers(ctx, r.Client)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list autoscalers in namespace")
+		recordReconcileError(reconcilePhaseGet)
+		return nil, err
+	}
+	return slices.DeleteFunc(conflicts, func(conflict autoscalingv1alpha1.ConflictingAutoscaler) bool {
+		return !conflict.Precedes(craneAutoscaler)
+	}), nil
+}
+
+// handleConflictingAutoscalers records that other autoscalers target the same workload and checks again later.
+// The HPA and VPA are left untouched in the meantime, as the autoscalers would fight over the workload.
+func (r *CranePodAutoscalerReconciler) handleConflictingAutoscalers(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, conflicts []autoscalingv1alpha1.ConflictingAutoscaler) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	message := craneAutoscaler.DescribeConflicts(conflicts)
+	logger.Info("Other autoscalers target the same workload, retrying later", "conflicts", conflicts)
+	r.event(craneAutoscaler, corev1.EventTypeWarning, eventReasonConflict, "%s", message)
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeConflictCraneAutoscaler,
+		Status: metav1.ConditionTrue, Reason: conflictReasonAutoscalers, Message: message})
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler,
+		Status: metav1.ConditionFalse, Reason: "Reconciling", Message: message})
+	return ctrl.Result{RequeueAfter: conflictRequeueInterval}, nil
+}
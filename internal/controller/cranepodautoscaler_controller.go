@@ -17,11 +17,14 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"time"
 
-	"github.com/google/go-cmp/cmp"
-
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,8 +32,12 @@ import (
 
 	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
 	hpav2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -40,15 +47,52 @@ import (
 const (
 	refVPA = "VPA"
 	refHPA = "HPA"
+	// refSplit is recorded as active autoscaler while both are active in Split mode
+	refSplit = "Split"
 	// typeAvailableCraneAutoscaler represents the status of the Deployment reconciliation
 	typeAvailableCraneAutoscaler       = "Available"
 	typeScalingDecisionCraneAutoscaler = "ScalingDecision"
+	typeTargetResolvedCraneAutoscaler  = "TargetResolved"
+	typeConflictCraneAutoscaler        = "Conflict"
+	// unresolvedTargetRequeueInterval is the interval after which resolving a missing target is retried
+	unresolvedTargetRequeueInterval = 30 * time.Second
+	// conflictRequeueInterval is the interval after which an HPA or VPA that cannot be claimed is checked again
+	conflictRequeueInterval = time.Minute
+)
+
+// Reasons of the events emitted for a cranepodautoscaler
+const (
+	eventReasonSwitchedToHPA    = "SwitchedToHPA"
+	eventReasonSwitchedToVPA    = "SwitchedToVPA"
+	eventReasonSwitchedToSplit  = "SwitchedToSplit"
+	eventReasonValidationFailed = "ValidationFailed"
+	eventReasonCreationFailed   = "CreationFailed"
+	eventReasonUpdateConflict   = "UpdateConflict"
+	eventReasonTargetNotFound   = "TargetNotResolved"
+	eventReasonAdopted          = "Adopted"
+	eventReasonConflict         = "Conflict"
 )
 
 // CranePodAutoscalerReconciler reconciles a CranePodAutoscaler object
 type CranePodAutoscalerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Clock is used to evaluate stabilization windows. Defaults to the real clock.
+	Clock clock.PassiveClock
+	// Recorder emits events for autoscaler switches and failures. No events are emitted if unset.
+	Recorder record.EventRecorder
+	// DefaultEvaluationInterval is the interval after which scaling decisions are re-evaluated
+	// if a cranepodautoscaler does not configure one. Zero disables the periodic re-evaluation.
+	DefaultEvaluationInterval time.Duration
+	// InPlacePodResize reports whether the cluster can resize pods in place. A VPA in InPlaceOrRecreate mode
+	// falls back to Recreate if unset.
+	InPlacePodResize bool
+	// APIReader reads the pods of the target during a handoff without caching them, as a cache would hold every pod
+	// in the cluster. Defaults to Client.
+	APIReader client.Reader
+	// Strategies are additional decision strategies by the name spec.behavior.strategy selects them with.
+	// The built-in Threshold strategy is always available.
+	Strategies map[autoscalingv1alpha1.DecisionStrategyName]DecisionStrategy
 }
 
 // +kubebuilder:rbac:groups=autoscaling.phihos.github.io,resources=cranepodautoscalers,verbs=get;list;watch;create;update;patch;delete
@@ -56,8 +100,9 @@ type CranePodAutoscalerReconciler struct {
 // +kubebuilder:rbac:groups=autoscaling.phihos.github.io,resources=cranepodautoscalers/finalizers,verbs=update
 // +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=autoscaling.k8s.io,resources=verticalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get
+// +kubebuilder:rbac:groups=*,resources=*/scale,verbs=get;update
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -74,145 +119,453 @@ func (r *CranePodAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.R
 			// If the custom resource is not found then it usually means that it was deleted or not created
 			// In this way, we will stop the reconciliation
 			logger.Info("cranepodautoscaler resource not found. Ignoring since object must be deleted")
+			deleteMetrics(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		logger.Error(err, "Failed to get cranepodautoscaler")
+		recordReconcileError(reconcilePhaseGet)
 		return ctrl.Result{}, err
 	}
 
-	if len(craneAutoscaler.Status.Conditions) == 0 {
-		meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler, Status: metav1.ConditionUnknown, Reason: "Reconciling", Message: "Starting reconciliation"})
-		if err := r.Status().Update(ctx, craneAutoscaler); err != nil {
-			logger.Error(err, "Failed to update cranepodautoscaler status")
-			return ctrl.Result{}, err
-		}
+	// Enforce the deletion policy before the cranepodautoscaler is removed.
+	if !craneAutoscaler.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, craneAutoscaler)
+	}
+	if err := r.reconcileFinalizer(ctx, craneAutoscaler); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileModeChangedBy(ctx, craneAutoscaler); err != nil {
+		return ctrl.Result{}, err
+	}
 
-		// Let's re-fetch the cranepodautoscaler Custom Resource after updating the status
-		// so that we have the latest state of the resource on the cluster, and we will avoid
-		// raising the error "the object has been modified, please apply
-		// your changes to the latest version and try again" which would re-trigger the reconciliation
-		// if we try to update it again in the following operations
-		if err := r.Get(ctx, req.NamespacedName, craneAutoscaler); err != nil {
-			logger.Error(err, "Failed to re-fetch cranepodautoscaler")
-			return ctrl.Result{}, err
+	// The status is computed in memory and written once at the end.
+	original := craneAutoscaler.DeepCopy()
+	result, err := r.reconcile(ctx, craneAutoscaler)
+	if statusErr := r.patchStatus(ctx, original, craneAutoscaler); statusErr != nil {
+		if err == nil {
+			err = statusErr
 		}
+		return ctrl.Result{}, err
+	}
+	r.announceTransition(original, craneAutoscaler)
+	return result, err
+}
+
+// reconcile decides which autoscaler to activate and reconciles the HPA and VPA accordingly.
+// It only changes the status in memory, which is written by the caller afterwards.
+func (r *CranePodAutoscalerReconciler) reconcile(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if len(craneAutoscaler.Status.Conditions) == 0 {
+		meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler, Status: metav1.ConditionUnknown, Reason: "Reconciling", Message: "Starting reconciliation"})
 	}
 
 	if err := craneAutoscaler.Validate(); err != nil {
+		r.event(craneAutoscaler, corev1.EventTypeWarning, eventReasonValidationFailed, "Validation failed: %s", err)
 		meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler,
 			Status: metav1.ConditionFalse, Reason: "Reconciling",
 			Message: fmt.Sprintf("Validation failed: %s", err)})
-		if err := r.Status().Update(ctx, craneAutoscaler); err != nil {
-			logger.Error(err, "Failed to update cranepodautoscaler status")
-			return ctrl.Result{}, err
-		}
 		return ctrl.Result{}, err
 	}
 
+	strategy, err := r.decisionStrategy(craneAutoscaler)
+	if err != nil {
+		r.event(craneAutoscaler, corev1.EventTypeWarning, eventReasonValidationFailed, "Validation failed: %s", err)
+		meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler,
+			Status: metav1.ConditionFalse, Reason: "Reconciling",
+			Message: fmt.Sprintf("Validation failed: %s", err)})
+		return ctrl.Result{}, err
+	}
+
+	updateOverrideStatus(craneAutoscaler)
+	if craneAutoscaler.Spec.Mode == autoscalingv1alpha1.ModeSuspended {
+		return r.reconcileSuspended(ctx, craneAutoscaler)
+	}
+
+	// Make sure the target exists before creating autoscalers for it.
+	target, err := r.resolveTarget(ctx, craneAutoscaler)
+	if err != nil {
+		return r.handleTargetResolutionError(ctx, craneAutoscaler, err)
+	}
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeTargetResolvedCraneAutoscaler,
+		Status: metav1.ConditionTrue, Reason: targetReasonResolved,
+		Message: fmt.Sprintf("Target %s %s has a scale subresource", craneAutoscaler.Spec.HPA.ScaleTargetRef.Kind, craneAutoscaler.Spec.HPA.ScaleTargetRef.Name)})
+
+	// Make sure no other autoscaler scales the target.
+	conflicts, err := r.findConflictingAutoscalers(ctx, craneAutoscaler)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(conflicts) > 0 {
+		return r.handleConflictingAutoscalers(ctx, craneAutoscaler, conflicts)
+	}
+
 	// Get or create VPA.
 	vpaCreated, vpa, err := r.getOrCreateVPA(ctx, craneAutoscaler)
 	if err != nil {
-		return ctrl.Result{}, err
+		return r.handleOwnershipConflict(ctx, craneAutoscaler, err)
 	}
 
 	// Get or create HPA.
 	hpaCreated, hpa, err := r.getOrCreateHPA(ctx, craneAutoscaler)
 	if err != nil {
-		return ctrl.Result{}, err
+		return r.handleOwnershipConflict(ctx, craneAutoscaler, err)
 	}
 
 	logger.Info("Got VPA and HPA", refVPA, vpa.Name, refHPA, hpa.Name)
+	if craneAutoscaler.Spec.Mode == autoscalingv1alpha1.ModeSplit {
+		return r.reconcileSplit(ctx, craneAutoscaler, hpa, vpa)
+	}
 
 	// Now we get to the core logic: We now decide which autoscaler to activate.
 	// The other autoscaler will be deactivated.
 	var activeAutoscaler string
 	var passiveAutoscaler string
-	lastScalingDecisionCondition := meta.FindStatusCondition(craneAutoscaler.Status.Conditions, typeScalingDecisionCraneAutoscaler)
-	if vpaCreated || hpaCreated {
+	var requeueAfter time.Duration
+	var trigger *ResourceUtilization
+	var transitionReason string
+	var utilizations []ResourceUtilization
+	var handingOff bool
+	var heldBack string
+	if vpa.Status.Recommendation != nil {
+		recommendations := uncappedRecommendations(craneAutoscaler.Status.HPABaseline, vpa.Status.Recommendation.ContainerRecommendations)
+		utilizations = getContainerResourceUtilizations(&craneAutoscaler.Spec.Behavior, recommendations)
+		r.updateContainerSelectionCondition(craneAutoscaler, recommendations, utilizations)
+	}
+	currentlyActiveAutoscaler := getCurrentlyActiveAutoscaler(craneAutoscaler)
+	if pinned := pinnedAutoscaler(craneAutoscaler.Spec.Mode); pinned != "" {
+		// Special case: The autoscaler was pinned manually, e.g. during an incident or a load test.
+		activeAutoscaler = pinned
+		passiveAutoscaler = refVPA
+		if pinned == refVPA {
+			passiveAutoscaler = refHPA
+		}
+		craneAutoscaler.Status.PendingSwitch = nil
+		transitionReason = fmt.Sprintf("Automatic switching is overridden: %s", describeOverride(craneAutoscaler.Status.Override))
+	} else if vpaCreated || hpaCreated {
 		// Special case: One or more autoscalers were just created.
 		//               When that happens we initialize the scaling decision with HPA
 		//               as this is the safer option in terms of availability.
 		activeAutoscaler = refHPA
 		passiveAutoscaler = refVPA
-	} else if lastScalingDecisionCondition == nil {
+		craneAutoscaler.Status.PendingSwitch = nil
+		transitionReason = "Autoscalers were created, starting with HPA"
+	} else if currentlyActiveAutoscaler == "" {
 		// Special case: Autoscalers already exist, but scaling decision has not been recorded to CRD status.
 		//               We default to "HPA" as this is the safer option in terms of availability.
 		//               This should not happen.
 		activeAutoscaler = refHPA
 		passiveAutoscaler = refVPA
+		craneAutoscaler.Status.PendingSwitch = nil
+		transitionReason = "No previous scaling decision recorded, defaulting to HPA"
+	} else if currentlyActiveAutoscaler == refSplit {
+		// Special case: Split mode was left. We start with HPA as this is the safer option in terms of availability.
+		activeAutoscaler = refHPA
+		passiveAutoscaler = refVPA
+		craneAutoscaler.Status.PendingSwitch = nil
+		transitionReason = "Split mode was left, starting with HPA"
 	} else if vpa.Status.Recommendation == nil {
 		// Special case: VPA has no recommendation yet (e.g. just created).
 		//               We default to HPA as this is the safer option in terms of availability.
 		activeAutoscaler = refHPA
 		passiveAutoscaler = refVPA
+		craneAutoscaler.Status.PendingSwitch = nil
+		transitionReason = "VPA has no recommendation, defaulting to HPA"
 	} else {
 		// Usual case: VPA and HPA both already exist.
-		// 			   Now our action depends on the current scaling mode.
-		currentlyActiveAutoscaler := lastScalingDecisionCondition.Reason
-		containerName, biggestUtilization := getBiggestContainerResourceUtilization(vpa.Status.Recommendation.ContainerRecommendations)
-		threshold := float32(craneAutoscaler.Spec.Behavior.VPACapacityThresholdPercent) / float32(100)
-		vpaOverThreshold := biggestUtilization > threshold
-		if currentlyActiveAutoscaler == refVPA {
-			// If the current scaling mode is VPA we need to check if the target has reached the utilization threshold.
-			// If yes, then we will switch to HPA.
-			if vpaOverThreshold {
+		// 			   The decision strategy selected in the behavior decides which autoscaler to activate.
+		decision, err := strategy.Decide(ctx, DecisionInput{CranePodAutoscaler: craneAutoscaler, HPA: hpa, VPA: vpa,
+			Target: target, Active: currentlyActiveAutoscaler, Utilizations: utilizations})
+		if err == nil && decision.Autoscaler != refHPA && decision.Autoscaler != refVPA {
+			err = fmt.Errorf("decision strategy selected unknown autoscaler %q", decision.Autoscaler)
+		}
+		if err != nil {
+			logger.Error(err, "Failed to decide which autoscaler to activate")
+			recordReconcileError(reconcilePhaseDecide)
+			return ctrl.Result{}, err
+		}
+		activeAutoscaler = decision.Autoscaler
+		passiveAutoscaler = refHPA
+		if activeAutoscaler == refHPA {
+			passiveAutoscaler = refVPA
+		}
+		trigger = decision.Trigger
+		transitionReason = decision.Reason
+
+		// The switch to VPA can additionally be held back while the HPA metrics are too close to their targets.
+		if currentlyActiveAutoscaler == refHPA && activeAutoscaler == refVPA {
+			if reason := hpaMetricsHoldBackSwitch(craneAutoscaler, hpa); reason != "" {
+				logger.Info("HPA metrics hold back the switch to VPA", "reason", reason)
 				activeAutoscaler = refHPA
 				passiveAutoscaler = refVPA
-				logger.Info("VPA target capacity threshold reached. Switching to HPA scaling.", "threshold", threshold, "container", containerName)
-			} else {
-				activeAutoscaler = refVPA
-				passiveAutoscaler = refHPA
+				heldBack = reason
+				transitionReason = "switch to VPA is held back: " + reason
+			}
+		}
+
+		// A switch only happens once its condition has held for the whole stabilization window.
+		// Until then the currently active autoscaler stays active. A handoff in progress has passed the window already.
+		if craneAutoscaler.Status.Handoff == nil || activeAutoscaler != refVPA {
+			activeAutoscaler, requeueAfter = r.stabilizeSwitch(craneAutoscaler, currentlyActiveAutoscaler, activeAutoscaler)
+		}
+		if requeueAfter > 0 {
+			logger.Info("Waiting for stabilization window to pass before switching autoscaler",
+				"to", craneAutoscaler.Status.PendingSwitch.To, "remaining", requeueAfter)
+			passiveAutoscaler = craneAutoscaler.Status.PendingSwitch.To
+		} else if activeAutoscaler == refVPA && currentlyActiveAutoscaler == refHPA {
+			// VPA mode is only declared once the handoff phase is complete. Until then the HPA stays active.
+			done, handoffRemaining, err := r.handoffToVPA(ctx, craneAutoscaler, hpa.Status.CurrentReplicas, vpa)
+			if err != nil {
+				return ctrl.Result{}, err
 			}
-		} else {
-			// If the current scaling mode is HPA we need to check two things:
-			//   1. Is the HPA at minimum replicas?
-			//   2. Is the VPA recommendation below threshold?
-			// If the answer is "yes" for both we will switch to VPA.
-			hpaDesiredReplicas := hpa.Status.DesiredReplicas
-			hpaMinReplicas := *hpa.Spec.MinReplicas
-			hpaAtMinReplicas := hpaDesiredReplicas <= hpaMinReplicas
-
-			if hpaAtMinReplicas && !vpaOverThreshold {
-				activeAutoscaler = refVPA
-				passiveAutoscaler = refHPA
-				logger.Info("HPA replicas at minimum and VPA is willing to scale down. Switching to VPA scaling.", "hpaMinReplicas", hpaMinReplicas)
-			} else {
+			handingOff = !done
+			if handingOff {
 				activeAutoscaler = refHPA
 				passiveAutoscaler = refVPA
+				requeueAfter = handoffRemaining
 			}
 		}
+		if requeueAfter == 0 && activeAutoscaler != currentlyActiveAutoscaler {
+			logger.Info("Switching autoscaler", "from", currentlyActiveAutoscaler, "to", activeAutoscaler, "trigger", trigger)
+		}
+	}
+	if !handingOff {
+		r.abortHandoff(ctx, craneAutoscaler, cmp.Or(transitionReason, "the condition for switching to VPA no longer holds"))
+	}
+	decisionMessage := fmt.Sprintf("Selected autoscaler is now %s", activeAutoscaler)
+	if craneAutoscaler.Status.Override != nil {
+		decisionMessage = fmt.Sprintf("%s; automatic switching is overridden: %s", decisionMessage, describeOverride(craneAutoscaler.Status.Override))
+	} else if trigger != nil {
+		decisionMessage = fmt.Sprintf("%s; most critical utilization is %s", decisionMessage, trigger)
+	}
+	if heldBack != "" {
+		decisionMessage = fmt.Sprintf("%s; switch to VPA is held back: %s", decisionMessage, heldBack)
 	}
-	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeScalingDecisionCraneAutoscaler, Status: metav1.ConditionTrue, Reason: activeAutoscaler, Message: fmt.Sprintf("Selected autoscaler is now %s", activeAutoscaler)})
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeScalingDecisionCraneAutoscaler, Status: metav1.ConditionTrue, Reason: activeAutoscaler, Message: decisionMessage})
+	if activeAutoscaler != currentlyActiveAutoscaler {
+		r.recordTransition(craneAutoscaler, currentlyActiveAutoscaler, activeAutoscaler, transitionReason, trigger)
+	}
+	r.updateHPABaseline(ctx, craneAutoscaler, currentlyActiveAutoscaler, activeAutoscaler, vpa)
+	baselineCheck, err := r.trackHPABaselineRollout(ctx, craneAutoscaler)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if baselineCheck > 0 && (requeueAfter == 0 || baselineCheck < requeueAfter) {
+		requeueAfter = baselineCheck
+	}
+	updateDecisionStatus(craneAutoscaler, activeAutoscaler, utilizations, hpa, vpa)
 
 	logger.Info("Decided which autoscaler to activate", "active", activeAutoscaler, "passive", passiveAutoscaler)
 
 	// Reconcile VPA resource
-	if err := r.reconcileVPA(ctx, craneAutoscaler, vpa, activeAutoscaler == refVPA); err != nil {
+	if err := r.reconcileVPA(ctx, craneAutoscaler, vpa, activeAutoscaler == refVPA || handoffEnablesVPA(craneAutoscaler)); err != nil {
 		logger.Error(err, "Failed to reconcile VPA")
-		return ctrl.Result{}, err
+		return r.handleOwnershipConflict(ctx, craneAutoscaler, err)
 	}
 
 	// Reconcile HPA resource
 	if err := r.reconcileHPA(ctx, craneAutoscaler, hpa, activeAutoscaler == refHPA); err != nil {
 		logger.Error(err, "Failed to reconcile HPA")
-		return ctrl.Result{}, err
+		return r.handleOwnershipConflict(ctx, craneAutoscaler, err)
 	}
 
+	setNoConflictCondition(craneAutoscaler)
 	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler, Status: metav1.ConditionTrue, Reason: "Reconciling", Message: "Reconciliation successful"})
-	if err := r.Status().Update(ctx, craneAutoscaler); err != nil {
-		logger.Error(err, "Failed to update cranepodautoscaler status")
-		return ctrl.Result{}, err
+	craneAutoscaler.Status.ObservedGeneration = craneAutoscaler.Generation
+
+	return ctrl.Result{RequeueAfter: r.nextEvaluation(craneAutoscaler, requeueAfter)}, nil
+}
+
+// reconcileSuspended records a suspended cranepodautoscaler in the status without touching the HPA and VPA.
+func (r *CranePodAutoscalerReconciler) reconcileSuspended(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Automatic switching is suspended, leaving HPA and VPA untouched", "by", craneAutoscaler.Status.Override.By)
+
+	craneAutoscaler.Status.PendingSwitch = nil
+	r.abortHandoff(ctx, craneAutoscaler, "automatic switching is suspended")
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler,
+		Status: metav1.ConditionTrue, Reason: "Suspended",
+		Message: fmt.Sprintf("HPA and VPA are left untouched: %s", describeOverride(craneAutoscaler.Status.Override))})
+	craneAutoscaler.Status.ObservedGeneration = craneAutoscaler.Generation
+	return ctrl.Result{RequeueAfter: r.nextEvaluation(craneAutoscaler, 0)}, nil
+}
+
+// nextEvaluation returns when the scaling decision has to be re-evaluated next: after the evaluation interval
+// or when a pending stabilization window expires, whichever comes first. Zero means no re-evaluation is scheduled.
+func (r *CranePodAutoscalerReconciler) nextEvaluation(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, stabilizationRemaining time.Duration) time.Duration {
+	interval := craneAutoscaler.Spec.Behavior.EvaluationInterval(r.DefaultEvaluationInterval)
+	if interval <= 0 || (stabilizationRemaining > 0 && stabilizationRemaining < interval) {
+		return stabilizationRemaining
+	}
+	return interval
+}
+
+// updateDecisionStatus records the active autoscaler together with a summary of the observed
+// HPA and VPA state in the status.
+func updateDecisionStatus(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, activeAutoscaler string, utilizations []ResourceUtilization, hpa *hpav2.HorizontalPodAutoscaler, vpa *vpav1.VerticalPodAutoscaler) {
+	status := &craneAutoscaler.Status
+	status.ActiveAutoscaler = activeAutoscaler
+	status.HPACurrentReplicas = hpa.Status.CurrentReplicas
+	status.HPADesiredReplicas = hpa.Status.DesiredReplicas
+	status.HPAMetrics, _ = observeHPAMetrics(hpa)
+	status.VPATarget = ""
+	if vpa.Status.Recommendation != nil {
+		status.VPATarget = summarizeVPATarget(vpa.Status.Recommendation.ContainerRecommendations)
+	}
+
+	// Report the utilization that is closest to triggering a switch away from the active autoscaler.
+	threshold := vpaToHPAThreshold
+	if activeAutoscaler == refHPA {
+		threshold = hpaToVPAThreshold
 	}
+	status.CurrentUtilizationPercent = nil
+	status.ThresholdPercent = nil
+	critical := mostCriticalUtilization(utilizations, threshold)
+	if critical == nil {
+		recordDecisionMetrics(craneAutoscaler, activeAutoscaler, nil, 0)
+		return
+	}
+	status.CurrentUtilizationPercent = ptr.To(int32(math.Round(float64(critical.Utilization) * 100)))
+	status.ThresholdPercent = ptr.To(int32(math.Round(float64(threshold(critical)) * 100)))
+	recordDecisionMetrics(craneAutoscaler, activeAutoscaler, critical, threshold(critical))
+}
+
+// getCurrentlyActiveAutoscaler returns the active autoscaler recorded in the status.
+// Objects written by older controller versions only record it as reason of the ScalingDecision condition.
+func getCurrentlyActiveAutoscaler(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) string {
+	if craneAutoscaler.Status.ActiveAutoscaler != "" {
+		return craneAutoscaler.Status.ActiveAutoscaler
+	}
+	if condition := meta.FindStatusCondition(craneAutoscaler.Status.Conditions, typeScalingDecisionCraneAutoscaler); condition != nil {
+		return condition.Reason
+	}
+	return ""
+}
+
+// recordTransition records a switch between autoscalers in the status and keeps the transition history bounded.
+// The transition is announced by announceTransition once the status was written.
+func (r *CranePodAutoscalerReconciler) recordTransition(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, from string, to string, reason string, trigger *ResourceUtilization) {
+	now := metav1.NewTime(r.now())
+	craneAutoscaler.Status.LastTransitionTime = &now
+	craneAutoscaler.Status.TriggeringContainer = ""
+	craneAutoscaler.Status.TriggeringResource = ""
+	if trigger != nil {
+		craneAutoscaler.Status.TriggeringContainer = trigger.Container
+		craneAutoscaler.Status.TriggeringResource = trigger.Resource
+	}
+
+	transitions := append(craneAutoscaler.Status.Transitions, autoscalingv1alpha1.AutoscalerTransition{
+		From:   from,
+		To:     to,
+		Time:   now,
+		Reason: reason,
+	})
+	if len(transitions) > autoscalingv1alpha1.MaxTransitionHistory {
+		transitions = transitions[len(transitions)-autoscalingv1alpha1.MaxTransitionHistory:]
+	}
+	craneAutoscaler.Status.Transitions = transitions
+}
+
+// announceTransition emits the event and metrics of a transition recorded during the reconciliation.
+// It is only called after the status was written, so that a transition that is recomputed after a conflicting
+// status write is announced once.
+func (r *CranePodAutoscalerReconciler) announceTransition(original *autoscalingv1alpha1.CranePodAutoscaler, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) {
+	transitions := craneAutoscaler.Status.Transitions
+	if len(transitions) == 0 || equality.Semantic.DeepEqual(original.Status.LastTransitionTime, craneAutoscaler.Status.LastTransitionTime) {
+		return
+	}
+	transition := transitions[len(transitions)-1]
+	var activeSince *time.Time
+	if original.Status.LastTransitionTime != nil {
+		activeSince = &original.Status.LastTransitionTime.Time
+	}
+	recordTransitionMetrics(craneAutoscaler, transition.From, transition.To, activeSince, transition.Time.Time)
+
+	eventReason := eventReasonSwitchedToHPA
+	switch transition.To {
+	case refVPA:
+		eventReason = eventReasonSwitchedToVPA
+	case refSplit:
+		eventReason = eventReasonSwitchedToSplit
+	}
+	message := fmt.Sprintf("Switched from %s to %s: %s", transition.From, transition.To, transition.Reason)
+	if transition.From == "" {
+		message = fmt.Sprintf("Activated %s: %s", transition.To, transition.Reason)
+	}
+	r.event(craneAutoscaler, corev1.EventTypeNormal, eventReason, "%s", message)
+}
+
+// event emits an event for the cranepodautoscaler if a recorder is configured.
+func (r *CranePodAutoscalerReconciler) event(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, eventType string, reason string, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(craneAutoscaler, eventType, reason, messageFmt, args...)
+}
+
+// recordUpdateConflict emits a warning event if an update of the given object failed because it was modified concurrently.
+func (r *CranePodAutoscalerReconciler) recordUpdateConflict(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, err error, object string) {
+	if apierrors.IsConflict(err) {
+		r.event(craneAutoscaler, corev1.EventTypeWarning, eventReasonUpdateConflict, "Conflict while updating %s: %s", object, err)
+	}
+}
 
-	return ctrl.Result{}, nil
+// patchStatus writes the status computed during a reconciliation with a single merge patch.
+// The patch is guarded by the resource version, so that a status computed from an outdated cranepodautoscaler
+// is not written. The conflict is returned instead and the status is recomputed from the latest version on requeue.
+func (r *CranePodAutoscalerReconciler) patchStatus(ctx context.Context, original *autoscalingv1alpha1.CranePodAutoscaler, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) error {
+	if equality.Semantic.DeepEqual(original.Status, craneAutoscaler.Status) {
+		return nil
+	}
+	err := r.Status().Patch(ctx, craneAutoscaler, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update cranepodautoscaler status")
+		recordReconcileError(reconcilePhaseStatus)
+		r.recordUpdateConflict(craneAutoscaler, err, "status")
+		return err
+	}
+	return nil
+}
+
+// stabilizeSwitch holds back a switch from the current to the desired autoscaler until the switching
+// condition has held for the whole stabilization window. The pending switch is tracked in the status.
+// It returns the autoscaler to activate now and, if the switch is held back, the remaining window.
+func (r *CranePodAutoscalerReconciler) stabilizeSwitch(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, current string, desired string) (string, time.Duration) {
+	if desired == current {
+		craneAutoscaler.Status.PendingSwitch = nil
+		return current, 0
+	}
+
+	rules := craneAutoscaler.Spec.Behavior.SwitchToHPA
+	if desired == refVPA {
+		rules = craneAutoscaler.Spec.Behavior.SwitchToVPA
+	}
+	now := r.now()
+	pending := craneAutoscaler.Status.PendingSwitch
+	if pending == nil || pending.To != desired {
+		pending = &autoscalingv1alpha1.PendingModeSwitch{To: desired, Since: metav1.NewTime(now)}
+		craneAutoscaler.Status.PendingSwitch = pending
+	}
+	if remaining := pending.Since.Add(rules.StabilizationWindow()).Sub(now); remaining > 0 {
+		return current, remaining
+	}
+	craneAutoscaler.Status.PendingSwitch = nil
+	return desired, 0
+}
+
+func (r *CranePodAutoscalerReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
 }
 
 func (r *CranePodAutoscalerReconciler) getOrCreateVPA(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) (bool, *vpav1.VerticalPodAutoscaler, error) {
 	logger := log.FromContext(ctx)
 	resourceKind := refVPA
 	vpa := &vpav1.VerticalPodAutoscaler{}
-	err := r.Get(ctx, types.NamespacedName{Name: craneAutoscaler.Name, Namespace: craneAutoscaler.Namespace}, vpa)
+	err := r.Get(ctx, types.NamespacedName{Name: craneAutoscaler.VPAName(), Namespace: craneAutoscaler.Namespace}, vpa)
 	if err != nil && apierrors.IsNotFound(err) {
 		vpa, err = r.NewVPAForAutoscaler(craneAutoscaler)
 		if err != nil {
@@ -221,11 +574,16 @@ func (r *CranePodAutoscalerReconciler) getOrCreateVPA(ctx context.Context, crane
 
 		logger.Info("Creating a new resource",
 			"resource.Kind", resourceKind, "resource.Namespace", vpa.Namespace, "resource.Name", vpa.Name)
-		if err = r.Create(ctx, vpa); err != nil {
-			return false, nil, r.handleAutoscalerCreationError(ctx, err, resourceKind, vpa.Namespace, vpa.Name)
+		created, err := r.createAutoscaler(ctx, craneAutoscaler, resourceKind, vpa)
+		if err != nil {
+			return false, nil, r.handleAutoscalerCreationError(ctx, err, resourceKind, craneAutoscaler, vpa.Name)
 		}
-		return true, vpa, nil
+		return created, vpa, nil
 	} else if err != nil {
+		recordReconcileError(reconcilePhaseGet)
+		return false, nil, err
+	}
+	if err := r.claimAutoscaler(ctx, craneAutoscaler, resourceKind, vpa, vpaHasSameTarget(craneAutoscaler, vpa)); err != nil {
 		return false, nil, err
 	}
 	return false, vpa, nil
@@ -235,7 +593,7 @@ func (r *CranePodAutoscalerReconciler) getOrCreateHPA(ctx context.Context, crane
 	logger := log.FromContext(ctx)
 	resourceKind := refHPA
 	hpa := &hpav2.HorizontalPodAutoscaler{}
-	err := r.Get(ctx, types.NamespacedName{Name: craneAutoscaler.Name, Namespace: craneAutoscaler.Namespace}, hpa)
+	err := r.Get(ctx, types.NamespacedName{Name: craneAutoscaler.HPAName(), Namespace: craneAutoscaler.Namespace}, hpa)
 	if err != nil && apierrors.IsNotFound(err) {
 		hpa, err = r.NewHPAForAutoscaler(craneAutoscaler)
 		if err != nil {
@@ -244,37 +602,87 @@ func (r *CranePodAutoscalerReconciler) getOrCreateHPA(ctx context.Context, crane
 
 		logger.Info("Creating a new resource",
 			"resource.Kind", resourceKind, "resource.Namespace", hpa.Namespace, "resource.Name", hpa.Name)
-		if err = r.Create(ctx, hpa); err != nil {
-			return false, nil, r.handleAutoscalerCreationError(ctx, err, resourceKind, hpa.Namespace, hpa.Name)
+		created, err := r.createAutoscaler(ctx, craneAutoscaler, resourceKind, hpa)
+		if err != nil {
+			return false, nil, r.handleAutoscalerCreationError(ctx, err, resourceKind, craneAutoscaler, hpa.Name)
 		}
-		return true, hpa, nil
+		return created, hpa, nil
 	} else if err != nil {
+		recordReconcileError(reconcilePhaseGet)
+		return false, nil, err
+	}
+	if err := r.claimAutoscaler(ctx, craneAutoscaler, resourceKind, hpa, hpaHasSameTarget(craneAutoscaler, hpa)); err != nil {
 		return false, nil, err
 	}
 	return false, hpa, nil
 }
 
-func (r *CranePodAutoscalerReconciler) handleAutoscalerCreationError(ctx context.Context, err error, resourceKind string, namespace string, name string) error {
+func (r *CranePodAutoscalerReconciler) handleAutoscalerCreationError(ctx context.Context, err error, resourceKind string, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, name string) error {
 	logger := log.FromContext(ctx)
 	logger.Error(err, "Failed to create new resource", "resource.Kind", resourceKind,
-		"resource.Namespace", namespace, "resource.Name", name)
+		"resource.Namespace", craneAutoscaler.Namespace, "resource.Name", name)
+	recordReconcileError(reconcilePhaseCreate)
+	r.event(craneAutoscaler, corev1.EventTypeWarning, eventReasonCreationFailed, "Failed to create %s %s: %s", resourceKind, name, err)
 	return err
 }
 
+// handleTargetResolutionError reports a target that could not be resolved in the status.
+// If the target is missing or cannot be scaled the reconciliation is retried later
+// without creating or changing any autoscaler.
+func (r *CranePodAutoscalerReconciler) handleTargetResolutionError(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, err error) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var resolutionErr *targetResolutionError
+	if !errors.As(err, &resolutionErr) {
+		logger.Error(err, "Failed to resolve target")
+		recordReconcileError(reconcilePhaseGet)
+		meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeTargetResolvedCraneAutoscaler,
+			Status: metav1.ConditionFalse, Reason: targetReasonResolutionError,
+			Message: fmt.Sprintf("Failed to resolve target: %s", err)})
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("Target could not be resolved, retrying later", "reason", resolutionErr.reason, "message", resolutionErr.message)
+	r.event(craneAutoscaler, corev1.EventTypeWarning, eventReasonTargetNotFound, "%s", resolutionErr.message)
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeTargetResolvedCraneAutoscaler,
+		Status: metav1.ConditionFalse, Reason: resolutionErr.reason, Message: resolutionErr.message})
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler,
+		Status: metav1.ConditionFalse, Reason: "Reconciling", Message: resolutionErr.message})
+	return ctrl.Result{RequeueAfter: unresolvedTargetRequeueInterval}, nil
+}
+
+// setNoConflictCondition reports that the HPA and VPA were applied without conflicts.
+func setNoConflictCondition(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) {
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeConflictCraneAutoscaler,
+		Status: metav1.ConditionFalse, Reason: conflictReasonNone, Message: "No other autoscaler targets the workload and the HPA and VPA are managed by this cranepodautoscaler"})
+}
+
+// handleOwnershipConflict reports an HPA or VPA that cannot be claimed by the cranepodautoscaler in the status
+// and checks again later without touching it. Other errors are returned as they are.
+func (r *CranePodAutoscalerReconciler) handleOwnershipConflict(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, err error) (ctrl.Result, error) {
+	var conflictErr *ownershipConflictError
+	if !errors.As(err, &conflictErr) {
+		return ctrl.Result{}, err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("Existing autoscaler cannot be managed, retrying later", "reason", conflictErr.reason, "message", conflictErr.message)
+	r.event(craneAutoscaler, corev1.EventTypeWarning, eventReasonConflict, "%s", conflictErr.message)
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeConflictCraneAutoscaler,
+		Status: metav1.ConditionTrue, Reason: conflictErr.reason, Message: conflictErr.message})
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler,
+		Status: metav1.ConditionFalse, Reason: "Reconciling", Message: conflictErr.message})
+	return ctrl.Result{RequeueAfter: conflictRequeueInterval}, nil
+}
+
 func (r *CranePodAutoscalerReconciler) handleAutoscalerDefinitionError(ctx context.Context, err error, resourceKind string, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) error {
 	logger := log.FromContext(ctx)
 	logger.Error(err, "Failed to define new resource for cranepodautoscaler", "resource.Kind", resourceKind)
 
-	// The following implementation will update the status
 	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler,
 		Status: metav1.ConditionFalse, Reason: "Reconciling",
 		Message: fmt.Sprintf("Failed to create %s for the custom resource (%s): (%s)", resourceKind, craneAutoscaler.Name, err)})
 
-	if err := r.Status().Update(ctx, craneAutoscaler); err != nil {
-		logger.Error(err, "Failed to update cranepodautoscaler status")
-		return err
-	}
-
 	return err
 }
 
@@ -303,58 +711,29 @@ func (r *CranePodAutoscalerReconciler) SetupWithManager(mgr ctrl.Manager) error
 		Complete(r)
 }
 
-func getBiggestContainerResourceUtilization(vpaContainerResources []vpav1.RecommendedContainerResources) (string, float32) {
-	utilization := float32(0.0)
-	containerName := "NOCONTAINER"
-	for _, containerResource := range vpaContainerResources {
-		var cpuUtilization float32
-		upperBoundCpu := containerResource.UpperBound.Cpu().MilliValue()
-		if upperBoundCpu > 0 {
-			targetCpu := containerResource.Target.Cpu().MilliValue()
-			cpuUtilization = float32(targetCpu) / float32(upperBoundCpu)
-		}
-		var memUtilization float32
-		upperBoundMem := containerResource.UpperBound.Memory().Value()
-		if upperBoundMem > 0 {
-			targetMem := containerResource.Target.Memory().Value()
-			memUtilization = float32(targetMem) / float32(upperBoundMem)
-		}
-
-		if cpuUtilization > utilization {
-			utilization = cpuUtilization
-			containerName = containerResource.ContainerName
-		}
-		if memUtilization > utilization {
-			utilization = memUtilization
-			containerName = containerResource.ContainerName
-		}
+func (r *CranePodAutoscalerReconciler) reconcileVPA(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, vpa *vpav1.VerticalPodAutoscaler, active bool) error {
+	if active {
+		return r.applyVPA(ctx, craneAutoscaler, vpa, craneAutoscaler.GenerateEnabledVPA())
 	}
-
-	return containerName, utilization
+	return r.applyVPA(ctx, craneAutoscaler, vpa, craneAutoscaler.GenerateDisabledVPA())
 }
 
-func (r *CranePodAutoscalerReconciler) reconcileVPA(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, vpa *vpav1.VerticalPodAutoscaler, active bool) error {
+// applyVPA applies the desired VPA and updates the given VPA with the result.
+func (r *CranePodAutoscalerReconciler) applyVPA(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, vpa *vpav1.VerticalPodAutoscaler, desiredVPA *vpav1.VerticalPodAutoscaler) error {
 	logger := log.FromContext(ctx)
-	var desiredVPA *vpav1.VerticalPodAutoscaler
-	if active {
-		desiredVPA = craneAutoscaler.GenerateEnabledVPA()
-	} else {
-		desiredVPA = craneAutoscaler.GenerateDisabledVPA()
-	}
+	r.fallBackFromInPlaceResize(ctx, craneAutoscaler, desiredVPA)
 	if err := ctrl.SetControllerReference(craneAutoscaler, desiredVPA, r.Scheme); err != nil {
 		return err
 	}
-	desiredVPA.Status = vpa.Status
-	if !cmp.Equal(desiredVPA.Spec, vpa.Spec) {
-		logger.Info("Updating VPA", "diff", cmp.Diff(desiredVPA.Spec, vpa.Spec))
-		vpa.Spec = desiredVPA.Spec
-		if err := r.Update(ctx, vpa); err != nil {
-			logger.Error(err, "Failed to update resource", "resource.Kind", refVPA,
-				"resource.Namespace", vpa.Namespace, "resource.Name", vpa.Name)
-			return err
-		}
-	}
 
+	// Applying an unchanged VPA is a no-op on the API server, so no difference needs to be computed here.
+	if err := r.applyAutoscaler(ctx, craneAutoscaler, refVPA, desiredVPA); err != nil {
+		logger.Error(err, "Failed to apply resource", "resource.Kind", refVPA,
+			"resource.Namespace", desiredVPA.Namespace, "resource.Name", desiredVPA.Name)
+		recordReconcileError(reconcilePhaseUpdate)
+		return err
+	}
+	desiredVPA.DeepCopyInto(vpa)
 	return nil
 }
 
@@ -363,23 +742,23 @@ func (r *CranePodAutoscalerReconciler) reconcileHPA(ctx context.Context, craneAu
 	var desiredHPA *hpav2.HorizontalPodAutoscaler
 	if active {
 		desiredHPA = craneAutoscaler.GenerateEnabledHPA()
+		if handoff := craneAutoscaler.Status.Handoff; handoff != nil {
+			desiredHPA.Spec.MaxReplicas = min(desiredHPA.Spec.MaxReplicas, handoff.MaxReplicas)
+		}
 	} else {
 		desiredHPA = craneAutoscaler.GenerateDisabledHPA()
 	}
 	if err := ctrl.SetControllerReference(craneAutoscaler, desiredHPA, r.Scheme); err != nil {
 		return err
 	}
-	desiredHPA.Status = hpa.Status
 
-	if !cmp.Equal(desiredHPA.Spec, hpa.Spec) {
-		logger.Info("Updating HPA", "diff", cmp.Diff(desiredHPA.Spec, hpa.Spec))
-		hpa.Spec = desiredHPA.Spec
-		if err := r.Update(ctx, hpa); err != nil {
-			logger.Error(err, "Failed to update resource", "resource.Kind", refHPA,
-				"resource.Namespace", hpa.Namespace, "resource.Name", hpa.Name)
-			return err
-		}
+	// Applying an unchanged HPA is a no-op on the API server, so no difference needs to be computed here.
+	if err := r.applyAutoscaler(ctx, craneAutoscaler, refHPA, desiredHPA); err != nil {
+		logger.Error(err, "Failed to apply resource", "resource.Kind", refHPA,
+			"resource.Namespace", desiredHPA.Namespace, "resource.Name", desiredHPA.Name)
+		recordReconcileError(reconcilePhaseUpdate)
+		return err
 	}
-
+	desiredHPA.DeepCopyInto(hpa)
 	return nil
 }
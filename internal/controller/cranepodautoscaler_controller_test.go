@@ -18,14 +18,24 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -72,15 +82,38 @@ func newCranePodAutoscaler(name string) *autoscalingv1alpha1.CranePodAutoscaler
 }
 
 func doReconcile(ctx context.Context, name string) (reconcile.Result, error) {
+	return doReconcileWithClock(ctx, name, clock.RealClock{})
+}
+
+func doReconcileWithClock(ctx context.Context, name string, clk clock.PassiveClock) (reconcile.Result, error) {
 	r := &CranePodAutoscalerReconciler{
 		Client: k8sClient,
 		Scheme: k8sClient.Scheme(),
+		Clock:  clk,
+	}
+	return r.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: name, Namespace: testNS},
+	})
+}
+
+func doReconcileWithRecorder(ctx context.Context, name string, recorder record.EventRecorder) (reconcile.Result, error) {
+	r := &CranePodAutoscalerReconciler{
+		Client:   k8sClient,
+		Scheme:   k8sClient.Scheme(),
+		Recorder: recorder,
 	}
 	return r.Reconcile(ctx, reconcile.Request{
 		NamespacedName: types.NamespacedName{Name: name, Namespace: testNS},
 	})
 }
 
+// newWatchClient returns a client for the test environment that can be wrapped by an interceptor.
+func newWatchClient() client.WithWatch {
+	c, err := client.NewWithWatch(cfg, client.Options{Scheme: k8sClient.Scheme()})
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return c
+}
+
 func setHPAStatus(ctx context.Context, name string, desiredReplicas int32) {
 	hpa := &hpav2.HorizontalPodAutoscaler{}
 	ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: testNS}, hpa)).To(Succeed())
@@ -111,6 +144,25 @@ func vpaContainerRecommendation(targetCPU, targetMem string) vpav1.RecommendedCo
 	}
 }
 
+// cappedVPAContainerRecommendation returns a recommendation capped to the given requests, as the VPA reports it
+// while the HPA baseline pins its resource policy.
+func cappedVPAContainerRecommendation(cappedCPU, cappedMem, uncappedCPU, uncappedMem string) vpav1.RecommendedContainerResources {
+	capped := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cappedCPU),
+		corev1.ResourceMemory: resource.MustParse(cappedMem),
+	}
+	return vpav1.RecommendedContainerResources{
+		ContainerName: "app",
+		Target:        capped,
+		LowerBound:    capped.DeepCopy(),
+		UpperBound:    capped.DeepCopy(),
+		UncappedTarget: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(uncappedCPU),
+			corev1.ResourceMemory: resource.MustParse(uncappedMem),
+		},
+	}
+}
+
 func vpaContainerRecommendationWithUpperBound(targetCPU, targetMem, upperCPU, upperMem string) vpav1.RecommendedContainerResources {
 	return vpav1.RecommendedContainerResources{
 		ContainerName: "app",
@@ -125,11 +177,51 @@ func vpaContainerRecommendationWithUpperBound(targetCPU, targetMem, upperCPU, up
 	}
 }
 
+func setHPAReplicas(ctx context.Context, name string, currentReplicas, desiredReplicas int32) {
+	hpa := &hpav2.HorizontalPodAutoscaler{}
+	ExpectWithOffset(1, k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: testNS}, hpa)).To(Succeed())
+	hpa.Status.CurrentReplicas = currentReplicas
+	hpa.Status.DesiredReplicas = desiredReplicas
+	ExpectWithOffset(1, k8sClient.Status().Update(ctx, hpa)).To(Succeed())
+}
+
+// newTargetPod returns a pod of the workload targeted by the cranepodautoscalers with the given requests.
+func newTargetPod(name, cpu, memory string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNS, Labels: map[string]string{"app": "my-app"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "app", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			}}},
+		},
+	}
+}
+
+// receivedEvents returns the events recorded so far.
+func receivedEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
 func cleanup(ctx context.Context, name string) {
 	nn := types.NamespacedName{Name: name, Namespace: testNS}
 
 	cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
 	if err := k8sClient.Get(ctx, nn, cpa); err == nil {
+		if len(cpa.Finalizers) > 0 {
+			cpa.Finalizers = nil
+			_ = k8sClient.Update(ctx, cpa)
+		}
 		_ = k8sClient.Delete(ctx, cpa)
 	}
 	hpa := &hpav2.HorizontalPodAutoscaler{}
@@ -384,168 +476,309 @@ var _ = Describe("CranePodAutoscaler Controller", func() {
 		})
 	})
 
-	Context("spec drift correction", func() {
-		It("corrects HPA MaxReplicas drift", func() {
-			const name = "test-hpa-drift"
+	Context("hysteresis band", func() {
+		It("only switches when utilization leaves the band between both thresholds", func() {
+			const name = "test-hysteresis"
 			defer cleanup(ctx, name)
 
 			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.VPAToHPAThresholdPercent = 80
+			cpa.Spec.Behavior.HPAToVPAThresholdPercent = 60
 			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
 
+			// First reconcile: defaults to HPA.
 			_, err := doReconcile(ctx, name)
 			Expect(err).NotTo(HaveOccurred())
 
-			// Set VPA recommendation and HPA status so re-reconcile doesn't panic on nil recommendation.
-			setHPAStatus(ctx, name, 5)
+			// HPA at min replicas, but utilization (70%) is inside the band -- should stay on HPA.
+			setHPAStatus(ctx, name, 2)
 			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
-				vpaContainerRecommendation("500m", "500Mi"),
+				vpaContainerRecommendation("700m", "700Mi"),
 			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
 
-			// Manually tamper with HPA MaxReplicas.
-			hpa := &hpav2.HorizontalPodAutoscaler{}
-			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
-			hpa.Spec.MaxReplicas = 99
-			Expect(k8sClient.Update(ctx, hpa)).To(Succeed())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("HPA"))
 
-			// Reconcile should correct it back to 10 (HPA is enabled).
+			// Utilization (60%) reaches the lower threshold -- should switch to VPA.
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("600m", "600Mi"),
+			})
 			_, err = doReconcile(ctx, name)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
-			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(10)))
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("VPA"))
+
+			// Utilization (75%) is inside the band again -- should stay on VPA.
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("750m", "750Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("VPA"))
+
+			// Utilization (85%) exceeds the upper threshold -- should switch to HPA.
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("850m", "850Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("HPA"))
 		})
+	})
 
-		It("corrects VPA UpdateMode drift", func() {
-			const name = "test-vpa-drift"
+	Context("events", func() {
+		It("emits normal events for every switch including utilization and threshold", func() {
+			const name = "test-events-switch"
 			defer cleanup(ctx, name)
 
 			cpa := newCranePodAutoscaler(name)
 			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
 
-			_, err := doReconcile(ctx, name)
+			recorder := record.NewFakeRecorder(10)
+			_, err := doReconcileWithRecorder(ctx, name, recorder)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(HavePrefix("Normal SwitchedToHPA Activated HPA")))
 
-			// Set VPA recommendation and HPA status so re-reconcile doesn't panic on nil recommendation.
-			setHPAStatus(ctx, name, 5)
+			setHPAStatus(ctx, name, 2)
 			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
-				vpaContainerRecommendation("500m", "500Mi"),
+				vpaContainerRecommendation("500m", "600Mi"),
 			})
-
-			// VPA should be disabled (Off) since HPA is active.
-			// Manually tamper to Recreate.
-			vpa := &vpav1.VerticalPodAutoscaler{}
-			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
-			vpa.Spec.UpdatePolicy.UpdateMode = ptr.To[vpav1.UpdateMode](vpav1.UpdateModeRecreate)
-			Expect(k8sClient.Update(ctx, vpa)).To(Succeed())
-
-			// Reconcile should correct it back to Off.
-			_, err = doReconcile(ctx, name)
+			_, err = doReconcileWithRecorder(ctx, name, recorder)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(And(
+				HavePrefix("Normal SwitchedToVPA Switched from HPA to VPA"),
+				ContainSubstring("at 60%"),
+				ContainSubstring("threshold 80%"),
+			)))
 
-			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
-			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeOff))
-		})
-	})
-
-	Context("edge cases", func() {
-		It("handles deleted resource gracefully", func() {
-			result, err := doReconcile(ctx, "nonexistent-resource")
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "900Mi"),
+			})
+			_, err = doReconcileWithRecorder(ctx, name, recorder)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result).To(Equal(reconcile.Result{}))
+			Expect(recorder.Events).To(Receive(And(
+				HavePrefix("Normal SwitchedToHPA Switched from VPA to HPA"),
+				ContainSubstring("threshold of 80%"),
+				ContainSubstring("memory of container app at 90%"),
+			)))
+
+			// No switch, no event.
+			_, err = doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
 		})
 
-		It("treats exactly 80% utilization as below threshold (stays on / transitions to VPA)", func() {
-			const name = "test-boundary"
+		It("reports a container selection that matches no recommended container", func() {
+			const name = "test-events-no-containers"
 			defer cleanup(ctx, name)
 
 			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.Containers = &autoscalingv1alpha1.ContainerSelection{Include: []string{"web"}}
 			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
 
-			_, err := doReconcile(ctx, name)
+			recorder := record.NewFakeRecorder(10)
+			_, err := doReconcileWithRecorder(ctx, name, recorder)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(HavePrefix("Normal SwitchedToHPA")))
 
-			// Set HPA at min replicas, VPA exactly at 80% (800m/1000m).
 			setHPAStatus(ctx, name, 2)
 			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
-				vpaContainerRecommendation("800m", "800Mi"),
+				vpaContainerRecommendation("500m", "500Mi"),
 			})
-
-			_, err = doReconcile(ctx, name)
+			_, err = doReconcileWithRecorder(ctx, name, recorder)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(And(
+				HavePrefix("Warning NoContainersSelected"),
+				ContainSubstring("[app]"),
+			)))
 
-			// 0.8 is NOT > 0.8, so should switch to VPA.
+			// The HPA at min replicas would otherwise switch to VPA, but there is no utilization to decide on.
 			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
-			decision := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
-			Expect(decision).NotTo(BeNil())
-			Expect(decision.Reason).To(Equal("VPA"))
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			condition := meta.FindStatusCondition(cpa.Status.Conditions, "ContainersSelected")
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+
+			// The warning is only emitted once.
+			_, err = doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
 		})
 
-		It("does not panic when VPA has no recommendation and defaults to HPA", func() {
-			const name = "test-nil-recommendation"
+		It("emits a warning event when validation fails", func() {
+			const name = "test-events-validation"
 			defer cleanup(ctx, name)
 
 			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.HPA.MinReplicas = nil
 			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
 
-			// First reconcile: creates HPA+VPA, defaults to HPA active.
-			_, err := doReconcile(ctx, name)
-			Expect(err).NotTo(HaveOccurred())
+			recorder := record.NewFakeRecorder(10)
+			_, err := doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).To(HaveOccurred())
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning ValidationFailed")))
+		})
 
-			// Set HPA at min replicas but leave VPA recommendation nil (no recommendation yet).
-			setHPAStatus(ctx, name, 2)
+		It("emits a warning event when creating an autoscaler fails", func() {
+			recorder := record.NewFakeRecorder(10)
+			r := &CranePodAutoscalerReconciler{Recorder: recorder}
+			cpa := newCranePodAutoscaler("test-events-creation")
+			err := r.handleAutoscalerCreationError(ctx, fmt.Errorf("boom"), "HPA", cpa, cpa.Name)
+			Expect(err).To(MatchError("boom"))
+			Expect(recorder.Events).To(Receive(Equal("Warning CreationFailed Failed to create HPA test-events-creation: boom")))
+		})
 
-			// Second reconcile: VPA has no recommendation. Must not panic and should stay on HPA.
-			_, err = doReconcile(ctx, name)
+		It("emits a warning event on update conflicts", func() {
+			const name = "test-events-conflict"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
 			Expect(err).NotTo(HaveOccurred())
 
+			staleCPA := &autoscalingv1alpha1.CranePodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), staleCPA)).To(Succeed())
 			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
-			decision := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
-			Expect(decision).NotTo(BeNil())
-			Expect(decision.Reason).To(Equal("HPA"))
+			cpa.Labels = map[string]string{"modified": "true"}
+			Expect(k8sClient.Update(ctx, cpa)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			r := &CranePodAutoscalerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Recorder: recorder}
+			staleCPA.Spec.DeletionPolicy = autoscalingv1alpha1.DeletionPolicyOrphanActiveHPA
+			err = r.reconcileFinalizer(ctx, staleCPA)
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning UpdateConflict Conflict while updating finalizers")))
 		})
 
-		It("does not panic when VPA upper bound is zero and treats utilization as zero", func() {
-			const name = "test-zero-upperbound"
+	})
+
+	Context("status", func() {
+		It("records the active autoscaler, utilization and transitions", func() {
+			const name = "test-status"
 			defer cleanup(ctx, name)
 
 			cpa := newCranePodAutoscaler(name)
 			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
 
-			// First reconcile: creates HPA+VPA, defaults to HPA active.
 			_, err := doReconcile(ctx, name)
 			Expect(err).NotTo(HaveOccurred())
 
-			// Set HPA at min replicas and VPA recommendation with zero upper bounds.
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			Expect(cpa.Status.ObservedGeneration).To(Equal(cpa.Generation))
+			Expect(cpa.Status.Transitions).To(HaveLen(1))
+			Expect(cpa.Status.Transitions[0].From).To(BeEmpty())
+			Expect(cpa.Status.Transitions[0].To).To(Equal("HPA"))
+
+			// Transition to VPA.
 			setHPAStatus(ctx, name, 2)
 			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
-				vpaContainerRecommendationWithUpperBound("500m", "500Mi", "0", "0"),
+				vpaContainerRecommendation("500m", "600Mi"),
 			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
 
-			// Reconcile must not panic and should transition to VPA
-			// since zero upper bound means zero utilization (below threshold).
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+			Expect(cpa.Status.CurrentUtilizationPercent).To(HaveValue(Equal(int32(60))))
+			Expect(cpa.Status.ThresholdPercent).To(HaveValue(Equal(int32(80))))
+			Expect(cpa.Status.HPADesiredReplicas).To(Equal(int32(2)))
+			Expect(cpa.Status.VPATarget).To(Equal("app: cpu=500m memory=600Mi"))
+			Expect(cpa.Status.LastTransitionTime).NotTo(BeNil())
+			Expect(cpa.Status.Transitions).To(HaveLen(2))
+			Expect(cpa.Status.Transitions[1].From).To(Equal("HPA"))
+			Expect(cpa.Status.Transitions[1].To).To(Equal("VPA"))
+			Expect(cpa.Status.Transitions[1].Reason).To(ContainSubstring("minimum"))
+
+			// Memory exceeds the threshold -- should switch to HPA and report the trigger.
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "900Mi"),
+			})
 			_, err = doReconcile(ctx, name)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
-			decision := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
-			Expect(decision).NotTo(BeNil())
-			Expect(decision.Reason).To(Equal("VPA"))
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			Expect(cpa.Status.CurrentUtilizationPercent).To(HaveValue(Equal(int32(90))))
+			Expect(cpa.Status.TriggeringContainer).To(Equal("app"))
+			Expect(cpa.Status.TriggeringResource).To(Equal(corev1.ResourceMemory))
+			Expect(cpa.Status.Transitions).To(HaveLen(3))
+			Expect(cpa.Status.Transitions[2].Reason).To(ContainSubstring("memory of container app"))
 		})
 
-		It("handles partial zero upper bound (only CPU zero) without panic", func() {
-			const name = "test-partial-zero-upperbound"
+		It("announces a transition only once its status was written", func() {
+			const name = "test-transition-status-conflict"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			// The first status write conflicts with a concurrent change of the cranepodautoscaler.
+			conflicted := false
+			conflictingClient := interceptor.NewClient(newWatchClient(), interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					if _, ok := obj.(*autoscalingv1alpha1.CranePodAutoscaler); ok && !conflicted {
+						conflicted = true
+						return apierrors.NewConflict(autoscalingv1alpha1.GroupVersion.WithResource("cranepodautoscalers").GroupResource(), obj.GetName(), errors.New("the object has been modified"))
+					}
+					return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+				},
+			})
+			recorder := record.NewFakeRecorder(10)
+			r := &CranePodAutoscalerReconciler{Client: conflictingClient, Scheme: k8sClient.Scheme(), Recorder: recorder}
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn(name)})
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+			Expect(receivedEvents(recorder)).NotTo(ContainElement(HavePrefix("Normal SwitchedTo")))
+
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: nn(name)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(receivedEvents(recorder)).To(ContainElement(HavePrefix("Normal SwitchedTo")))
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: nn(name)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(receivedEvents(recorder)).NotTo(ContainElement(HavePrefix("Normal SwitchedTo")))
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.Transitions).To(HaveLen(1))
+		})
+
+		It("keeps the transition history bounded", func() {
+			r := &CranePodAutoscalerReconciler{}
+			cpa := newCranePodAutoscaler("test-history")
+			for i := 0; i < 2*autoscalingv1alpha1.MaxTransitionHistory; i++ {
+				r.recordTransition(cpa, "HPA", "VPA", fmt.Sprintf("transition %d", i), nil)
+			}
+			Expect(cpa.Status.Transitions).To(HaveLen(autoscalingv1alpha1.MaxTransitionHistory))
+			Expect(cpa.Status.Transitions[len(cpa.Status.Transitions)-1].Reason).To(Equal(fmt.Sprintf("transition %d", 2*autoscalingv1alpha1.MaxTransitionHistory-1)))
+		})
+	})
+
+	Context("per-resource thresholds", func() {
+		It("switches to HPA based on the threshold of the triggering resource", func() {
+			const name = "test-resource-thresholds"
 			defer cleanup(ctx, name)
 
 			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.ResourceThresholds = []autoscalingv1alpha1.ResourceThreshold{
+				{Resource: corev1.ResourceMemory, VPAToHPAThresholdPercent: 95},
+				{Resource: corev1.ResourceCPU, VPAToHPAThresholdPercent: 70, HPAToVPAThresholdPercent: 60},
+			}
 			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
 
 			_, err := doReconcile(ctx, name)
 			Expect(err).NotTo(HaveOccurred())
 
-			// Transition to VPA first.
+			// Transition to VPA.
 			setHPAStatus(ctx, name, 2)
 			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
-				vpaContainerRecommendationWithUpperBound("500m", "500Mi", "1000m", "1000Mi"),
+				vpaContainerRecommendation("500m", "500Mi"),
 			})
 			_, err = doReconcile(ctx, name)
 			Expect(err).NotTo(HaveOccurred())
@@ -553,83 +786,1796 @@ var _ = Describe("CranePodAutoscaler Controller", func() {
 			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
 			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("VPA"))
 
-			// Now set CPU upper bound to zero but memory above threshold (90%).
-			// Memory utilization alone should trigger the switch to HPA.
+			// Memory at 90% is below its own threshold of 95% -- should stay on VPA.
 			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
-				vpaContainerRecommendationWithUpperBound("500m", "900Mi", "0", "1000Mi"),
+				vpaContainerRecommendation("500m", "900Mi"),
 			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
 
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("VPA"))
+
+			// CPU at 75% exceeds its threshold of 70% -- should switch to HPA and report the trigger.
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("750m", "900Mi"),
+			})
 			_, err = doReconcile(ctx, name)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
 			decision := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
-			Expect(decision).NotTo(BeNil())
 			Expect(decision.Reason).To(Equal("HPA"))
+			Expect(decision.Message).To(ContainSubstring("cpu of container app"))
 		})
+	})
 
-		It("triggers HPA transition when memory exceeds threshold even if CPU is below", func() {
-			const name = "test-mem-threshold"
+	Context("stabilization windows", func() {
+		It("holds back a switch until its condition held for the whole window", func() {
+			const name = "test-stabilization"
 			defer cleanup(ctx, name)
 
 			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.SwitchToVPA = &autoscalingv1alpha1.ModeSwitchRules{StabilizationWindowSeconds: ptr.To[int32](300)}
+			cpa.Spec.Behavior.SwitchToHPA = &autoscalingv1alpha1.ModeSwitchRules{StabilizationWindowSeconds: ptr.To[int32](60)}
 			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
 
-			// First reconcile: HPA active.
-			_, err := doReconcile(ctx, name)
+			clk := clocktesting.NewFakePassiveClock(time.Now().Truncate(time.Second))
+
+			// First reconcile: defaults to HPA.
+			_, err := doReconcileWithClock(ctx, name, clk)
 			Expect(err).NotTo(HaveOccurred())
 
-			// Transition to VPA first.
+			// Conditions for HPA->VPA hold, but the window has just started.
 			setHPAStatus(ctx, name, 2)
 			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
 				vpaContainerRecommendation("500m", "500Mi"),
 			})
-			_, err = doReconcile(ctx, name)
+			result, err := doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(300 * time.Second))
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("HPA"))
+			Expect(cpa.Status.PendingSwitch).NotTo(BeNil())
+			Expect(cpa.Status.PendingSwitch.To).To(Equal("VPA"))
+
+			// Still inside the window.
+			clk.SetTime(clk.Now().Add(200 * time.Second))
+			result, err = doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(100 * time.Second))
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("HPA"))
+
+			// Window has passed -- should switch to VPA.
+			clk.SetTime(clk.Now().Add(100 * time.Second))
+			result, err = doReconcileWithClock(ctx, name, clk)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
 
 			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
 			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("VPA"))
+			Expect(cpa.Status.PendingSwitch).To(BeNil())
+		})
 
-			// Now set CPU below threshold (50%) but memory above threshold (90%).
+		It("resets the window when the switching condition stops holding", func() {
+			const name = "test-stabilization-reset"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.SwitchToVPA = &autoscalingv1alpha1.ModeSwitchRules{StabilizationWindowSeconds: ptr.To[int32](300)}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			clk := clocktesting.NewFakePassiveClock(time.Now().Truncate(time.Second))
+
+			_, err := doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Conditions for HPA->VPA hold and the window starts.
+			setHPAStatus(ctx, name, 2)
 			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
-				vpaContainerRecommendation("500m", "900Mi"),
+				vpaContainerRecommendation("500m", "500Mi"),
 			})
+			_, err = doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
 
-			_, err = doReconcile(ctx, name)
+			// HPA scales out again -- the pending switch is dropped.
+			setHPAStatus(ctx, name, 5)
+			clk.SetTime(clk.Now().Add(200 * time.Second))
+			_, err = doReconcileWithClock(ctx, name, clk)
 			Expect(err).NotTo(HaveOccurred())
 
-			// Should switch to HPA because memory utilization exceeds threshold.
 			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
-			decision := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
-			Expect(decision).NotTo(BeNil())
-			Expect(decision.Reason).To(Equal("HPA"))
+			Expect(cpa.Status.PendingSwitch).To(BeNil())
+
+			// Conditions hold again -- the window starts over.
+			setHPAStatus(ctx, name, 2)
+			clk.SetTime(clk.Now().Add(200 * time.Second))
+			result, err := doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(300 * time.Second))
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("HPA"))
 		})
 	})
 
-	Context("owner references", func() {
-		It("sets controller owner references on both HPA and VPA", func() {
-			const name = "test-ownerrefs"
+	Context("HPA metric threshold", func() {
+		It("holds back the switch to VPA until the HPA metrics are below the threshold", func() {
+			const name = "test-hpa-metric-threshold"
 			defer cleanup(ctx, name)
 
 			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.SwitchToVPA = &autoscalingv1alpha1.ModeSwitchRules{HPAMetricThresholdPercent: ptr.To[int32](50)}
 			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
 
 			_, err := doReconcile(ctx, name)
 			Expect(err).NotTo(HaveOccurred())
 
-			// Re-fetch the CR to get its UID.
+			// HPA at min replicas and VPA below threshold, but the HPA reports no CPU utilization yet.
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
 			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			condition := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
+			Expect(condition.Reason).To(Equal("HPA"))
+			Expect(condition.Message).To(ContainSubstring("switch to VPA is held back: HPA reports no current value for metric cpu"))
 
+			// The CPU utilization is still above half of its target.
 			hpa := &hpav2.HorizontalPodAutoscaler{}
 			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
-			Expect(hpa.OwnerReferences).To(HaveLen(1))
-			ownerRef := hpa.OwnerReferences[0]
-			Expect(ownerRef.UID).To(Equal(cpa.UID))
-			Expect(*ownerRef.Controller).To(BeTrue())
-			Expect(*ownerRef.BlockOwnerDeletion).To(BeTrue())
+			hpa.Status.CurrentMetrics = []hpav2.MetricStatus{{Type: hpav2.ResourceMetricSourceType, Resource: &hpav2.ResourceMetricStatus{
+				Name:    corev1.ResourceCPU,
+				Current: hpav2.MetricValueStatus{AverageUtilization: ptr.To[int32](60)},
+			}}}
+			Expect(k8sClient.Status().Update(ctx, hpa)).To(Succeed())
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
 
-			vpa := &vpav1.VerticalPodAutoscaler{}
-			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("HPA"))
+			Expect(cpa.Status.HPAMetrics).To(Equal([]autoscalingv1alpha1.HPAMetricStatus{
+				{Type: hpav2.ResourceMetricSourceType, Name: "cpu", Current: "60%", Target: "80%", PercentOfTarget: 75},
+			}))
+
+			// Once it dropped below half of its target the switch happens.
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			hpa.Status.CurrentMetrics[0].Resource.Current.AverageUtilization = ptr.To[int32](30)
+			Expect(k8sClient.Status().Update(ctx, hpa)).To(Succeed())
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("VPA"))
+			Expect(cpa.Status.HPAMetrics[0].PercentOfTarget).To(Equal(int32(38)))
+		})
+	})
+
+	Context("mode overrides", func() {
+		It("keeps the VPA active while pinned and reports who pinned it", func() {
+			const name = "test-mode-pinned-vpa"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Pin the VPA although it has no recommendation.
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			patch := client.MergeFrom(cpa.DeepCopy())
+			cpa.Spec.Mode = autoscalingv1alpha1.ModePinnedVPA
+			Expect(k8sClient.Patch(ctx, cpa, patch, client.FieldOwner("load-test"))).To(Succeed())
+
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+			Expect(cpa.Status.Override).NotTo(BeNil())
+			Expect(cpa.Status.Override.Mode).To(Equal(autoscalingv1alpha1.ModePinnedVPA))
+			Expect(cpa.Status.Override.By).To(Equal("load-test"))
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Message).To(ContainSubstring("overridden"))
+
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeRecreate))
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(2)))
+
+			// VPA above threshold does not switch to HPA while pinned.
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("900m", "900Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+
+			// Back to automatic switching.
+			patch = client.MergeFrom(cpa.DeepCopy())
+			cpa.Spec.Mode = autoscalingv1alpha1.ModeAuto
+			Expect(k8sClient.Patch(ctx, cpa, patch)).To(Succeed())
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			Expect(cpa.Status.Override).To(BeNil())
+		})
+
+		It("keeps the HPA active while pinned", func() {
+			const name = "test-mode-pinned-hpa"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Mode = autoscalingv1alpha1.ModePinnedHPA
+			cpa.Annotations = map[string]string{autoscalingv1alpha1.ModeChangedByAnnotation: "jane: incident 42"}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Conditions for HPA->VPA hold, but the HPA is pinned.
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			Expect(cpa.Status.Override.By).To(Equal("jane: incident 42"))
+
+			// The annotation is removed together with the override.
+			patch := client.MergeFrom(cpa.DeepCopy())
+			cpa.Spec.Mode = autoscalingv1alpha1.ModeAuto
+			Expect(k8sClient.Patch(ctx, cpa, patch)).To(Succeed())
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.Override).To(BeNil())
+			Expect(cpa.Annotations).NotTo(HaveKey(autoscalingv1alpha1.ModeChangedByAnnotation))
+		})
+
+		It("leaves both autoscalers untouched while suspended", func() {
+			const name = "test-mode-suspended"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			cpa.Spec.Mode = autoscalingv1alpha1.ModeSuspended
+			Expect(k8sClient.Update(ctx, cpa)).To(Succeed())
+
+			// Manual changes to the HPA are kept.
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			hpa.Spec.MaxReplicas = 42
+			Expect(k8sClient.Update(ctx, hpa)).To(Succeed())
+
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(42)))
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.Override.Mode).To(Equal(autoscalingv1alpha1.ModeSuspended))
+			available := meta.FindStatusCondition(cpa.Status.Conditions, "Available")
+			Expect(available.Reason).To(Equal("Suspended"))
+		})
+	})
+
+	Context("split mode", func() {
+		It("keeps both autoscalers active with the VPA controlling the resources the HPA does not use", func() {
+			const name = "test-mode-split"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Mode = autoscalingv1alpha1.ModeSplit
+			cpa.Spec.HPA.Metrics = []hpav2.MetricSpec{{
+				Type: hpav2.ResourceMetricSourceType,
+				Resource: &hpav2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: hpav2.MetricTarget{Type: hpav2.UtilizationMetricType, AverageUtilization: ptr.To[int32](70)},
+				},
+			}}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("Split"))
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("Split"))
+
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(10)))
+
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeRecreate))
+			Expect(vpa.Spec.ResourcePolicy.ContainerPolicies).To(HaveLen(1))
+			Expect(vpa.Spec.ResourcePolicy.ContainerPolicies[0].ContainerName).To(Equal("*"))
+			Expect(*vpa.Spec.ResourcePolicy.ContainerPolicies[0].ControlledResources).To(ConsistOf(corev1.ResourceMemory))
+		})
+
+		It("starts with the HPA when Split mode is left", func() {
+			const name = "test-mode-split-left"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Mode = autoscalingv1alpha1.ModeSplit
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			cpa.Spec.Mode = autoscalingv1alpha1.ModeAuto
+			Expect(k8sClient.Update(ctx, cpa)).To(Succeed())
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			Expect(cpa.Status.Transitions[len(cpa.Status.Transitions)-1].From).To(Equal("Split"))
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeOff))
+			Expect(vpa.Spec.ResourcePolicy).To(BeNil())
+		})
+	})
+
+	Context("adoption of existing autoscalers", func() {
+		newExistingHPA := func(name string) *hpav2.HorizontalPodAutoscaler {
+			return &hpav2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNS},
+				Spec: hpav2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: hpav2.CrossVersionObjectReference{Kind: "Deployment", Name: "my-app", APIVersion: "apps/v1"},
+					MinReplicas:    ptr.To[int32](1),
+					MaxReplicas:    3,
+				},
+			}
+		}
+
+		expectConflict := func(name string, reason string) {
+			cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+			ExpectWithOffset(1, k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			conflict := meta.FindStatusCondition(cpa.Status.Conditions, "Conflict")
+			ExpectWithOffset(1, conflict).NotTo(BeNil())
+			ExpectWithOffset(1, conflict.Status).To(Equal(metav1.ConditionTrue))
+			ExpectWithOffset(1, conflict.Reason).To(Equal(reason))
+		}
+
+		It("refuses an unowned HPA without adoptExisting", func() {
+			const name = "test-adopt-disabled"
+			defer cleanup(ctx, name)
+
+			Expect(k8sClient.Create(ctx, newExistingHPA(name))).To(Succeed())
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			result, err := doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning Conflict")))
+			expectConflict(name, "NotAdopted")
+
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.OwnerReferences).To(BeEmpty())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(3)))
+		})
+
+		It("adopts an unowned HPA with the same target", func() {
+			const name = "test-adopt-enabled"
+			defer cleanup(ctx, name)
+
+			Expect(k8sClient.Create(ctx, newExistingHPA(name))).To(Succeed())
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.AdoptExisting = true
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(cpa.Status.Conditions, "Conflict")).To(BeTrue())
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(metav1.IsControlledBy(hpa, cpa)).To(BeTrue())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(10)))
+		})
+
+		It("refuses an unowned HPA with a different target", func() {
+			const name = "test-adopt-other-target"
+			defer cleanup(ctx, name)
+
+			existing := newExistingHPA(name)
+			existing.Spec.ScaleTargetRef.Name = "other-app"
+			Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.AdoptExisting = true
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			expectConflict(name, "TargetMismatch")
+		})
+
+		It("refuses an HPA controlled by another controller", func() {
+			const name = "test-adopt-foreign"
+			defer cleanup(ctx, name)
+
+			existing := newExistingHPA(name)
+			existing.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "example.com/v1", Kind: "Autoscaler", Name: "other", UID: "1234",
+				Controller: ptr.To(true),
+			}}
+			Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.AdoptExisting = true
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			expectConflict(name, "OwnedByAnotherController")
+		})
+
+		It("reports an HPA created concurrently after it was found missing", func() {
+			const name = "test-adopt-concurrent"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			// The HPA is created by another writer between the lookup and the apply with the same values.
+			racingClient := interceptor.NewClient(newWatchClient(), interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*hpav2.HorizontalPodAutoscaler); ok && key == nn(name) {
+						existing := newExistingHPA(name)
+						existing.Spec.MinReplicas = ptr.To[int32](2)
+						existing.Spec.MaxReplicas = 10
+						Expect(c.Create(ctx, existing, client.FieldOwner("other-controller"))).To(Succeed())
+						return apierrors.NewNotFound(hpav2.Resource("horizontalpodautoscalers"), name)
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			})
+			recorder := record.NewFakeRecorder(10)
+			r := &CranePodAutoscalerReconciler{Client: racingClient, Scheme: k8sClient.Scheme(), Recorder: recorder}
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn(name)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(And(
+				HavePrefix("Warning OwnershipConflict Took over HPA "+name),
+				ContainSubstring("other-controller"),
+			)))
+		})
+	})
+
+	Context("conflicting autoscalers", func() {
+		It("leaves the workload to a standalone HPA targeting it", func() {
+			const name = "test-conflicting-hpa"
+			defer cleanup(ctx, name)
+			defer cleanup(ctx, "standalone-hpa")
+
+			standalone := &hpav2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "standalone-hpa", Namespace: testNS},
+				Spec: hpav2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: hpav2.CrossVersionObjectReference{Kind: "Deployment", Name: "my-app", APIVersion: "apps/v1"},
+					MaxReplicas:    3,
+				},
+			}
+			Expect(k8sClient.Create(ctx, standalone)).To(Succeed())
+			Expect(k8sClient.Create(ctx, newCranePodAutoscaler(name))).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			result, err := doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(recorder.Events).To(Receive(Equal("Warning Conflict Deployment my-app is already targeted by HorizontalPodAutoscaler standalone-hpa")))
+
+			cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "Conflict").Reason).To(Equal("ConflictingAutoscalers"))
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), &hpav2.HorizontalPodAutoscaler{}))).To(BeTrue())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), &vpav1.VerticalPodAutoscaler{}))).To(BeTrue())
+
+			Expect(k8sClient.Delete(ctx, standalone)).To(Succeed())
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(cpa.Status.Conditions, "Conflict")).To(BeTrue())
+			Expect(k8sClient.Get(ctx, nn(name), &hpav2.HorizontalPodAutoscaler{})).To(Succeed())
+		})
+
+		It("reports another cranepodautoscaler targeting the same workload", func() {
+			const first = "test-conflicting-first"
+			const second = "test-conflicting-second"
+			defer cleanup(ctx, first)
+			defer cleanup(ctx, second)
+
+			Expect(k8sClient.Create(ctx, newCranePodAutoscaler(first))).To(Succeed())
+			_, err := doReconcile(ctx, first)
+			Expect(err).NotTo(HaveOccurred())
+
+			// The HPA and VPA of the first cranepodautoscaler are reported through their owner.
+			Expect(k8sClient.Create(ctx, newCranePodAutoscaler(second))).To(Succeed())
+			_, err = doReconcile(ctx, second)
+			Expect(err).NotTo(HaveOccurred())
+
+			cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(second), cpa)).To(Succeed())
+			conflict := meta.FindStatusCondition(cpa.Status.Conditions, "Conflict")
+			Expect(conflict).NotTo(BeNil())
+			Expect(conflict.Status).To(Equal(metav1.ConditionTrue))
+			Expect(conflict.Reason).To(Equal("ConflictingAutoscalers"))
+			Expect(conflict.Message).To(Equal("Deployment my-app is already targeted by CranePodAutoscaler " + first))
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(second), &hpav2.HorizontalPodAutoscaler{}))).To(BeTrue())
+
+			// The first cranepodautoscaler keeps scaling the workload.
+			_, err = doReconcile(ctx, first)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(first), cpa)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(cpa.Status.Conditions, "Conflict")).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(cpa.Status.Conditions, "Available")).To(BeTrue())
+		})
+
+		It("keeps scaling the workload when a newer HPA targets it", func() {
+			const name = "test-conflicting-early"
+			defer cleanup(ctx, name)
+			defer cleanup(ctx, "test-conflicting-late-hpa")
+
+			Expect(k8sClient.Create(ctx, newCranePodAutoscaler(name))).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Created in the same second, the HPA is ordered after the cranepodautoscaler by its name.
+			late := &hpav2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-conflicting-late-hpa", Namespace: testNS},
+				Spec: hpav2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: hpav2.CrossVersionObjectReference{Kind: "Deployment", Name: "my-app", APIVersion: "apps/v1"},
+					MaxReplicas:    3,
+				},
+			}
+			Expect(k8sClient.Create(ctx, late)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			_, err = doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(receivedEvents(recorder)).NotTo(ContainElement(HavePrefix("Warning Conflict")))
+			cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(cpa.Status.Conditions, "Conflict")).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(cpa.Status.Conditions, "Available")).To(BeTrue())
+		})
+	})
+
+	Context("deletion policy", func() {
+		It("does not add a finalizer for the Delete policy", func() {
+			const name = "test-deletion-delete"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Spec.DeletionPolicy).To(Equal(autoscalingv1alpha1.DeletionPolicyDelete))
+			Expect(cpa.Finalizers).To(BeEmpty())
+		})
+
+		It("keeps an enabled HPA and deletes the VPA for OrphanActiveHPA", func() {
+			const name = "test-deletion-orphan"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.DeletionPolicy = autoscalingv1alpha1.DeletionPolicyOrphanActiveHPA
+			cpa.Spec.Mode = autoscalingv1alpha1.ModePinnedVPA
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Finalizers).To(ContainElement("autoscaling.phihos.github.io/deletion-policy"))
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(2)))
+
+			Expect(k8sClient.Delete(ctx, cpa)).To(Succeed())
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), cpa))).To(BeTrue())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), &vpav1.VerticalPodAutoscaler{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(10)))
+			Expect(hpa.OwnerReferences).To(BeEmpty())
+		})
+
+		It("deletes both autoscalers and scales the target for RestoreReplicas", func() {
+			const name = "test-deletion-restore"
+			defer cleanup(ctx, name)
+
+			deployment := newDeployment(name)
+			Expect(k8sClient.Create(ctx, deployment)).To(Succeed())
+			defer func() { Expect(k8sClient.Delete(ctx, deployment)).To(Succeed()) }()
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.HPA.ScaleTargetRef.Name = name
+			cpa.Spec.VPA.TargetRef.Name = name
+			cpa.Spec.DeletionPolicy = autoscalingv1alpha1.DeletionPolicyRestoreReplicas
+			cpa.Spec.RestoreReplicas = ptr.To[int32](4)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Delete(ctx, cpa)).To(Succeed())
+			recorder := record.NewFakeRecorder(10)
+			_, err = doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(Equal("Normal RestoredReplicas Scaled Deployment " + name + " to 4 replicas")))
+
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), cpa))).To(BeTrue())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), &hpav2.HorizontalPodAutoscaler{}))).To(BeTrue())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), &vpav1.VerticalPodAutoscaler{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, nn(name), deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(Equal(int32(4))))
+		})
+	})
+
+	Context("target resolution", func() {
+		It("resolves StatefulSets through the scale subresource", func() {
+			const name = "test-target-statefulset"
+			defer cleanup(ctx, name)
+
+			deployment := newDeployment(name)
+			statefulSet := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNS},
+				Spec: appsv1.StatefulSetSpec{
+					Selector: deployment.Spec.Selector,
+					Template: deployment.Spec.Template,
+				},
+			}
+			Expect(k8sClient.Create(ctx, statefulSet)).To(Succeed())
+			defer func() { Expect(k8sClient.Delete(ctx, statefulSet)).To(Succeed()) }()
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.HPA.ScaleTargetRef.Kind = "StatefulSet"
+			cpa.Spec.HPA.ScaleTargetRef.Name = name
+			cpa.Spec.VPA.TargetRef.Kind = "StatefulSet"
+			cpa.Spec.VPA.TargetRef.Name = name
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			targetResolved := meta.FindStatusCondition(cpa.Status.Conditions, "TargetResolved")
+			Expect(targetResolved).NotTo(BeNil())
+			Expect(targetResolved.Status).To(Equal(metav1.ConditionTrue))
+			Expect(k8sClient.Get(ctx, nn(name), &hpav2.HorizontalPodAutoscaler{})).To(Succeed())
+		})
+
+		It("requeues without creating autoscalers if the target is missing", func() {
+			const name = "test-target-missing"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.HPA.ScaleTargetRef.Name = "missing-app"
+			cpa.Spec.VPA.TargetRef.Name = "missing-app"
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			result, err := doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning TargetNotResolved")))
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			targetResolved := meta.FindStatusCondition(cpa.Status.Conditions, "TargetResolved")
+			Expect(targetResolved).NotTo(BeNil())
+			Expect(targetResolved.Status).To(Equal(metav1.ConditionFalse))
+			Expect(targetResolved.Reason).To(Equal("TargetNotFound"))
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), &hpav2.HorizontalPodAutoscaler{}))).To(BeTrue())
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), &vpav1.VerticalPodAutoscaler{}))).To(BeTrue())
+		})
+
+		It("reports kinds that are not served by the cluster", func() {
+			const name = "test-target-unknown-kind"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.HPA.ScaleTargetRef.APIVersion = "argoproj.io/v1alpha1"
+			cpa.Spec.HPA.ScaleTargetRef.Kind = "Rollout"
+			cpa.Spec.VPA.TargetRef.APIVersion = "argoproj.io/v1alpha1"
+			cpa.Spec.VPA.TargetRef.Kind = "Rollout"
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			result, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			targetResolved := meta.FindStatusCondition(cpa.Status.Conditions, "TargetResolved")
+			Expect(targetResolved).NotTo(BeNil())
+			Expect(targetResolved.Reason).To(Equal("UnknownKind"))
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), &hpav2.HorizontalPodAutoscaler{}))).To(BeTrue())
+		})
+	})
+
+	Context("periodic re-evaluation", func() {
+		It("requeues after the evaluation interval", func() {
+			const name = "test-evaluation-interval"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.EvaluationIntervalSeconds = ptr.To[int32](120)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			r := &CranePodAutoscalerReconciler{
+				Client:                    k8sClient,
+				Scheme:                    k8sClient.Scheme(),
+				DefaultEvaluationInterval: 5 * time.Minute,
+			}
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn(name)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(120 * time.Second))
+		})
+
+		It("requeues after the evaluation interval in split mode and while suspended", func() {
+			const name = "test-evaluation-interval-modes"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.EvaluationIntervalSeconds = ptr.To[int32](120)
+			cpa.Spec.HPA.Metrics = []hpav2.MetricSpec{{
+				Type: hpav2.ResourceMetricSourceType,
+				Resource: &hpav2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: hpav2.MetricTarget{Type: hpav2.UtilizationMetricType, AverageUtilization: ptr.To[int32](70)},
+				},
+			}}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			r := &CranePodAutoscalerReconciler{
+				Client:                    k8sClient,
+				Scheme:                    k8sClient.Scheme(),
+				DefaultEvaluationInterval: 5 * time.Minute,
+			}
+			request := reconcile.Request{NamespacedName: nn(name)}
+			_, err := r.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, mode := range []autoscalingv1alpha1.Mode{autoscalingv1alpha1.ModeSplit, autoscalingv1alpha1.ModeSuspended} {
+				Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+				cpa.Spec.Mode = mode
+				Expect(k8sClient.Update(ctx, cpa)).To(Succeed())
+
+				result, err := r.Reconcile(ctx, request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(120*time.Second), "mode %s", mode)
+			}
+		})
+
+		It("falls back to the default interval and prefers an earlier stabilization deadline", func() {
+			r := &CranePodAutoscalerReconciler{DefaultEvaluationInterval: 5 * time.Minute}
+			cpa := newCranePodAutoscaler("test-evaluation-default")
+			Expect(r.nextEvaluation(cpa, 0)).To(Equal(5 * time.Minute))
+			Expect(r.nextEvaluation(cpa, time.Minute)).To(Equal(time.Minute))
+			Expect(r.nextEvaluation(cpa, 10*time.Minute)).To(Equal(5 * time.Minute))
+
+			// 0 disables the periodic re-evaluation, but pending stabilization windows still expire on time.
+			cpa.Spec.Behavior.EvaluationIntervalSeconds = ptr.To[int32](0)
+			Expect(r.nextEvaluation(cpa, 0)).To(BeZero())
+			Expect(r.nextEvaluation(cpa, 10*time.Minute)).To(Equal(10 * time.Minute))
+		})
+	})
+
+	Context("passive VPA update mode", func() {
+		It("runs the passive VPA in Initial mode if requested", func() {
+			const name = "test-passive-initial"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.PassiveVPAUpdateMode = autoscalingv1alpha1.PassiveVPAUpdateModeInitial
+			// Without metrics the HPA would scale on CPU utilization.
+			cpa.Spec.HPA.Metrics = []hpav2.MetricSpec{{
+				Type: hpav2.ResourceMetricSourceType,
+				Resource: &hpav2.ResourceMetricSource{
+					Name:   corev1.ResourceMemory,
+					Target: hpav2.MetricTarget{Type: hpav2.AverageValueMetricType, AverageValue: ptr.To(resource.MustParse("500Mi"))},
+				},
+			}}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeInitial))
+		})
+
+		It("falls back to Off if the HPA scales on the utilization of a resource the VPA controls", func() {
+			const name = "test-passive-initial-utilization"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.PassiveVPAUpdateMode = autoscalingv1alpha1.PassiveVPAUpdateModeInitial
+			cpa.Spec.HPA.Metrics = []hpav2.MetricSpec{{
+				Type: hpav2.ResourceMetricSourceType,
+				Resource: &hpav2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: hpav2.MetricTarget{Type: hpav2.UtilizationMetricType, AverageUtilization: ptr.To[int32](70)},
+				},
+			}}
+			Expect(cpa.Warnings()).To(ConsistOf(ContainSubstring("falls back to Off")))
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeOff))
+		})
+
+		It("keeps Initial mode if the VPA does not control the resource the HPA scales on", func() {
+			cpa := newCranePodAutoscaler("test-passive-initial-memory")
+			cpa.Spec.Behavior.PassiveVPAUpdateMode = autoscalingv1alpha1.PassiveVPAUpdateModeInitial
+			cpa.Spec.HPA.Metrics = []hpav2.MetricSpec{{
+				Type: hpav2.ResourceMetricSourceType,
+				Resource: &hpav2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: hpav2.MetricTarget{Type: hpav2.UtilizationMetricType, AverageUtilization: ptr.To[int32](70)},
+				},
+			}}
+			cpa.Spec.VPA.ResourcePolicy = &vpav1.PodResourcePolicy{ContainerPolicies: []vpav1.ContainerResourcePolicy{{
+				ContainerName:       "*",
+				ControlledResources: &[]corev1.ResourceName{corev1.ResourceMemory},
+			}}}
+			Expect(cpa.Warnings()).To(BeEmpty())
+			Expect(*cpa.GenerateDisabledVPA().Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeInitial))
+		})
+	})
+
+	Context("HPA baseline", func() {
+		It("freezes the VPA target as baseline while the HPA is active", func() {
+			const name = "test-hpa-baseline-target"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.HPABaseline = &autoscalingv1alpha1.HPABaseline{Source: autoscalingv1alpha1.HPABaselineSourceTarget}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("700m", "700Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+			Expect(cpa.Status.HPABaseline).To(BeNil())
+
+			// The VPA exceeds its threshold and the HPA takes over.
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("900m", "900Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			Expect(cpa.Status.HPABaseline).NotTo(BeNil())
+			Expect(cpa.Status.HPABaseline.Source).To(Equal(autoscalingv1alpha1.HPABaselineSourceTarget))
+			Expect(cpa.Status.HPABaseline.Containers).To(HaveLen(1))
+			Expect(cpa.Status.HPABaseline.Containers[0].ContainerName).To(Equal("app"))
+			Expect(cpa.Status.HPABaseline.Containers[0].Requests.Cpu().String()).To(Equal("900m"))
+
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeInitial))
+			Expect(vpa.Spec.ResourcePolicy.ContainerPolicies).To(HaveLen(1))
+			policy := vpa.Spec.ResourcePolicy.ContainerPolicies[0]
+			Expect(policy.ContainerName).To(Equal("app"))
+			Expect(policy.MinAllowed.Cpu().String()).To(Equal("900m"))
+			Expect(policy.MaxAllowed.Memory().String()).To(Equal("900Mi"))
+
+			// The baseline stays frozen while the recommendation moves on. The VPA caps its recommendation to the
+			// pinned baseline, the utilization is taken from the uncapped target instead.
+			setHPAStatus(ctx, name, 5)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				cappedVPAContainerRecommendation("900m", "900Mi", "950m", "950Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.HPABaseline.Containers[0].Requests.Cpu().String()).To(Equal("900m"))
+			Expect(cpa.Status.CurrentUtilizationPercent).To(HaveValue(Equal(int32(106))))
+
+			// The baseline is released once the VPA is active again.
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				cappedVPAContainerRecommendation("900m", "900Mi", "450m", "450Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+			Expect(cpa.Status.CurrentUtilizationPercent).To(HaveValue(Equal(int32(50))))
+			Expect(cpa.Status.HPABaseline).To(BeNil())
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeRecreate))
+			Expect(vpa.Spec.ResourcePolicy).To(BeNil())
+		})
+
+		It("applies explicit baseline requests", func() {
+			const name = "test-hpa-baseline-explicit"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.HPABaseline = &autoscalingv1alpha1.HPABaseline{
+				Source: autoscalingv1alpha1.HPABaselineSourceExplicit,
+				Containers: []autoscalingv1alpha1.ContainerRequests{{
+					ContainerName: "app",
+					Requests:      corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+				}},
+			}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("700m", "700Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("900m", "900Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			Expect(cpa.Status.HPABaseline.Source).To(Equal(autoscalingv1alpha1.HPABaselineSourceExplicit))
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(vpa.Spec.ResourcePolicy.ContainerPolicies[0].MinAllowed.Cpu().String()).To(Equal("250m"))
+			Expect(vpa.Spec.ResourcePolicy.ContainerPolicies[0].MaxAllowed.Cpu().String()).To(Equal("250m"))
+		})
+
+		It("enables the HPA right away and reports the rollout of the baseline to the running pods", func() {
+			const name = "test-hpa-baseline-rollout"
+			defer cleanup(ctx, name)
+
+			// The pod still runs with the requests the VPA applied last.
+			outdated := newTargetPod(name+"-outdated", "700m", "700Mi")
+			Expect(k8sClient.Create(ctx, outdated)).To(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, outdated) }()
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.HPABaseline = &autoscalingv1alpha1.HPABaseline{Source: autoscalingv1alpha1.HPABaselineSourceTarget}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("700m", "700Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+
+			// The VPA exceeds its threshold and the HPA takes over.
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("900m", "900Mi"),
+			})
+			result, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(handoffPodCheckInterval))
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			Expect(cpa.Status.HPABaseline.Applied).To(BeFalse())
+			Expect(cpa.Status.HPABaseline.UpdatedPods).To(Equal(int32(0)))
+			Expect(cpa.Status.HPABaseline.TotalPods).To(Equal(int32(1)))
+			progressing := meta.FindStatusCondition(cpa.Status.Conditions, "Progressing")
+			Expect(progressing.Status).To(Equal(metav1.ConditionTrue))
+			Expect(progressing.Reason).To(Equal("ApplyingHPABaseline"))
+
+			// The HPA scales right away while the VPA updates the pod to the baseline.
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(10)))
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeRecreate))
+			Expect(vpa.Spec.ResourcePolicy.ContainerPolicies[0].MinAllowed.Cpu().String()).To(Equal("900m"))
+
+			// The VPA evicted the outdated pod and it was replaced with one carrying the baseline, up to rounding.
+			Expect(k8sClient.Delete(ctx, outdated, client.GracePeriodSeconds(0))).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(ctx, client.ObjectKeyFromObject(outdated), &corev1.Pod{})
+			}).Should(Satisfy(apierrors.IsNotFound))
+			replaced := newTargetPod(name+"-replaced", "920m", "900Mi")
+			Expect(k8sClient.Create(ctx, replaced)).To(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, replaced) }()
+
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.HPABaseline.Applied).To(BeTrue())
+			Expect(cpa.Status.HPABaseline.UpdatedPods).To(Equal(int32(1)))
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "Progressing").Reason).To(Equal("HPABaselineApplied"))
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeInitial))
+		})
+
+		It("only applies the baseline to new pods once the rollout timed out", func() {
+			const name = "test-hpa-baseline-timeout"
+			defer cleanup(ctx, name)
+
+			outdated := newTargetPod(name+"-outdated", "700m", "700Mi")
+			Expect(k8sClient.Create(ctx, outdated)).To(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, outdated) }()
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.HPABaseline = &autoscalingv1alpha1.HPABaseline{
+				Source: autoscalingv1alpha1.HPABaselineSourceTarget, TimeoutSeconds: ptr.To[int32](120),
+			}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			clk := clocktesting.NewFakePassiveClock(time.Now().Truncate(time.Second))
+			_, err := doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("700m", "700Mi"),
+			})
+			_, err = doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("900m", "900Mi"),
+			})
+			_, err = doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.HPABaseline.Applied).To(BeFalse())
+
+			clk.SetTime(clk.Now().Add(120 * time.Second))
+			_, err = doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.HPABaseline.Applied).To(BeTrue())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "Progressing").Reason).To(Equal("HPABaselineTimedOut"))
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeInitial))
+		})
+	})
+
+	Context("handoff to VPA", func() {
+		It("steps the HPA max replicas down before declaring VPA mode", func() {
+			const name = "test-handoff-step-down"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.SwitchToVPA = &autoscalingv1alpha1.ModeSwitchRules{Handoff: &autoscalingv1alpha1.HandoffRules{
+				MaxReplicasStep: ptr.To[int32](2), StepIntervalSeconds: ptr.To[int32](60),
+			}}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			clk := clocktesting.NewFakePassiveClock(time.Now().Truncate(time.Second))
+			_, err := doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+
+			// The HPA desires min replicas, but still runs 6.
+			setHPAReplicas(ctx, name, 6, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			for _, maxReplicas := range []int32{6, 4, 2} {
+				result, err := doReconcileWithClock(ctx, name, clk)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(60 * time.Second))
+
+				Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+				Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+				Expect(cpa.Status.Handoff).NotTo(BeNil())
+				Expect(cpa.Status.Handoff.Phase).To(Equal(autoscalingv1alpha1.HandoffPhaseSteppingDown))
+				Expect(cpa.Status.Handoff.MaxReplicas).To(Equal(maxReplicas))
+				progressing := meta.FindStatusCondition(cpa.Status.Conditions, "Progressing")
+				Expect(progressing.Status).To(Equal(metav1.ConditionTrue))
+				Expect(progressing.Reason).To(Equal("SteppingDown"))
+
+				Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+				Expect(hpa.Spec.MaxReplicas).To(Equal(maxReplicas))
+				Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+				Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeOff))
+
+				clk.SetTime(clk.Now().Add(60 * time.Second))
+			}
+
+			// The last step has settled, VPA mode is declared.
+			_, err = doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+			Expect(cpa.Status.Handoff).To(BeNil())
+			progressing := meta.FindStatusCondition(cpa.Status.Conditions, "Progressing")
+			Expect(progressing.Status).To(Equal(metav1.ConditionFalse))
+			Expect(progressing.Reason).To(Equal("HandoffComplete"))
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeRecreate))
+		})
+
+		It("waits for the VPA to update the pods before declaring VPA mode", func() {
+			const name = "test-handoff-pod-updates"
+			defer cleanup(ctx, name)
+
+			updated := newTargetPod(name+"-updated", "500m", "500Mi")
+			outdated := newTargetPod(name+"-outdated", "100m", "500Mi")
+			Expect(k8sClient.Create(ctx, updated)).To(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, updated) }()
+			Expect(k8sClient.Create(ctx, outdated)).To(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, outdated) }()
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.SwitchToVPA = &autoscalingv1alpha1.ModeSwitchRules{Handoff: &autoscalingv1alpha1.HandoffRules{
+				UpdatedPodsPercent: ptr.To[int32](100),
+			}}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			setHPAReplicas(ctx, name, 2, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+
+			result, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(handoffPodCheckInterval))
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			Expect(cpa.Status.Handoff.Phase).To(Equal(autoscalingv1alpha1.HandoffPhaseWaitingForPodUpdates))
+			Expect(cpa.Status.Handoff.UpdatedPods).To(Equal(int32(1)))
+			Expect(cpa.Status.Handoff.TotalPods).To(Equal(int32(2)))
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "Progressing").Reason).To(Equal("WaitingForPodUpdates"))
+
+			// The HPA is held at min replicas while the VPA updates the pods.
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(2)))
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeRecreate))
+
+			// The VPA evicted the outdated pod.
+			Expect(k8sClient.Delete(ctx, outdated, client.GracePeriodSeconds(0))).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(ctx, client.ObjectKeyFromObject(outdated), &corev1.Pod{})
+			}).Should(Satisfy(apierrors.IsNotFound))
+
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+			Expect(cpa.Status.Handoff).To(BeNil())
+		})
+
+		It("declares VPA mode once the handoff timed out", func() {
+			const name = "test-handoff-timeout"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.SwitchToVPA = &autoscalingv1alpha1.ModeSwitchRules{Handoff: &autoscalingv1alpha1.HandoffRules{
+				UpdatedPodsPercent: ptr.To[int32](100), TimeoutSeconds: ptr.To[int32](120),
+			}}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			clk := clocktesting.NewFakePassiveClock(time.Now().Truncate(time.Second))
+			_, err := doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+			setHPAReplicas(ctx, name, 2, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+
+			// No pod of the target was updated.
+			_, err = doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+
+			clk.SetTime(clk.Now().Add(120 * time.Second))
+			_, err = doReconcileWithClock(ctx, name, clk)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "Progressing").Reason).To(Equal("HandoffTimedOut"))
+		})
+
+		It("aborts the handoff if the switching condition stops holding", func() {
+			const name = "test-handoff-abort"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.SwitchToVPA = &autoscalingv1alpha1.ModeSwitchRules{Handoff: &autoscalingv1alpha1.HandoffRules{
+				MaxReplicasStep: ptr.To[int32](1),
+			}}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			setHPAReplicas(ctx, name, 4, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.Handoff).NotTo(BeNil())
+
+			// Load increases again and the HPA wants to scale out.
+			setHPAReplicas(ctx, name, 4, 5)
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("HPA"))
+			Expect(cpa.Status.Handoff).To(BeNil())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "Progressing").Reason).To(Equal("HandoffAborted"))
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(10)))
+		})
+	})
+
+	Context("in-place pod resize", func() {
+		It("falls back to Recreate if the cluster cannot resize pods in place", func() {
+			const name = "test-inplace-fallback"
+			defer cleanup(ctx, name)
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Mode = autoscalingv1alpha1.ModePinnedVPA
+			cpa.Spec.VPA.UpdatePolicy.UpdateMode = ptr.To(vpav1.UpdateModeInPlaceOrRecreate)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			_, err := doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeRecreate))
+			Expect(receivedEvents(recorder)).To(ContainElement(HavePrefix("Warning InPlaceResizeUnavailable")))
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			resizing := meta.FindStatusCondition(cpa.Status.Conditions, "ResizingInPlace")
+			Expect(resizing.Status).To(Equal(metav1.ConditionFalse))
+			Expect(resizing.Reason).To(Equal("InPlaceResizeUnavailable"))
+
+			// The fallback is only reported once.
+			_, err = doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(receivedEvents(recorder)).NotTo(ContainElement(HavePrefix("Warning InPlaceResizeUnavailable")))
+
+			// The condition is removed once spec.vpa no longer requests InPlaceOrRecreate.
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			cpa.Spec.VPA.UpdatePolicy.UpdateMode = ptr.To(vpav1.UpdateModeRecreate)
+			Expect(k8sClient.Update(ctx, cpa)).To(Succeed())
+			_, err = doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ResizingInPlace")).To(BeNil())
+		})
+
+		It("resizes in place and checks the handoff more often while pods are being resized", func() {
+			const name = "test-inplace-handoff"
+			defer cleanup(ctx, name)
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.VPA.UpdatePolicy.UpdateMode = ptr.To(vpav1.UpdateModeInPlaceOrRecreate)
+			cpa.Spec.Behavior.SwitchToVPA = &autoscalingv1alpha1.ModeSwitchRules{Handoff: &autoscalingv1alpha1.HandoffRules{
+				UpdatedPodsPercent: ptr.To[int32](100),
+			}}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			// The VPA resized the pod spec, but the kubelet still runs the container with the old requests.
+			// The test environment does not resize pods in place, so the kubelet reporting the requests is simulated.
+			pod := newTargetPod(name+"-pod", "500m", "500Mi")
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, pod) }()
+			appliedCPU := resource.MustParse("100m")
+			kubeletClient := interceptor.NewClient(newWatchClient(), interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if err := c.List(ctx, list, opts...); err != nil {
+						return err
+					}
+					if pods, ok := list.(*corev1.PodList); ok {
+						for i := range pods.Items {
+							pods.Items[i].Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "app", Resources: &corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceCPU: appliedCPU, corev1.ResourceMemory: resource.MustParse("500Mi")},
+							}}}
+						}
+					}
+					return nil
+				},
+			})
+
+			r := &CranePodAutoscalerReconciler{Client: k8sClient, APIReader: kubeletClient, Scheme: k8sClient.Scheme(), InPlacePodResize: true}
+			request := reconcile.Request{NamespacedName: nn(name)}
+			_, err := r.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			setHPAReplicas(ctx, name, 2, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+
+			result, err := r.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(inPlaceHandoffPodCheckInterval))
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.Handoff.UpdatedPods).To(Equal(int32(0)))
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "Progressing").Message).To(ContainSubstring("1 are being resized in place"))
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeInPlaceOrRecreate))
+
+			// The kubelet applied the resize.
+			appliedCPU = resource.MustParse("500m")
+			_, err = r.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+		})
+	})
+
+	Context("decision strategy", func() {
+		It("uses a decision strategy registered with the controller", func() {
+			const name = "test-strategy-registered"
+			defer cleanup(ctx, name)
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.Strategy = "AlwaysVPA"
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			r := &CranePodAutoscalerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(),
+				Strategies: map[autoscalingv1alpha1.DecisionStrategyName]DecisionStrategy{"AlwaysVPA": alwaysStrategy(refVPA)}}
+			request := reconcile.Request{NamespacedName: nn(name)}
+			_, err := r.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			// The HPA is far above min replicas, which would keep the threshold strategy on HPA.
+			setHPAStatus(ctx, name, 8)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+			_, err = r.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+			Expect(cpa.Status.Transitions[len(cpa.Status.Transitions)-1].Reason).To(Equal("always VPA"))
+		})
+
+		It("reports an unknown decision strategy", func() {
+			const name = "test-strategy-unknown"
+			defer cleanup(ctx, name)
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.Behavior.Strategy = "Unknown"
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).To(MatchError(`unknown decision strategy "Unknown"`))
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			available := meta.FindStatusCondition(cpa.Status.Conditions, "Available")
+			Expect(available.Status).To(Equal(metav1.ConditionFalse))
+			Expect(available.Message).To(ContainSubstring("unknown decision strategy"))
+		})
+	})
+
+	Context("spec drift correction", func() {
+		It("corrects HPA MaxReplicas drift", func() {
+			const name = "test-hpa-drift"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Set VPA recommendation and HPA status so re-reconcile doesn't panic on nil recommendation.
+			setHPAStatus(ctx, name, 5)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+
+			// Manually tamper with HPA MaxReplicas.
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			hpa.Spec.MaxReplicas = 99
+			Expect(k8sClient.Update(ctx, hpa)).To(Succeed())
+
+			// Reconcile should correct it back to 10 (HPA is enabled).
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(10)))
+		})
+
+		It("names the autoscalers after their templates and corrects metadata drift", func() {
+			const name = "test-template"
+			defer cleanup(ctx, name)
+			defer cleanup(ctx, name+"-hpa")
+			defer cleanup(ctx, name+"-vpa")
+
+			cpa := newCranePodAutoscaler(name)
+			cpa.Spec.HPATemplate = &autoscalingv1alpha1.AutoscalerTemplate{Metadata: autoscalingv1alpha1.AutoscalerTemplateMetadata{
+				NameSuffix:  "-hpa",
+				Labels:      map[string]string{"cost-center": "1234"},
+				Annotations: map[string]string{"example.com/owner": "team-a"},
+			}}
+			cpa.Spec.VPATemplate = &autoscalingv1alpha1.AutoscalerTemplate{Metadata: autoscalingv1alpha1.AutoscalerTemplateMetadata{
+				NameSuffix: "-vpa",
+				Labels:     map[string]string{"cost-center": "1234"},
+			}}
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name+"-hpa"), hpa)).To(Succeed())
+			Expect(hpa.Labels).To(HaveKeyWithValue("cost-center", "1234"))
+			Expect(hpa.Annotations).To(HaveKeyWithValue("example.com/owner", "team-a"))
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name+"-vpa"), vpa)).To(Succeed())
+			Expect(vpa.Labels).To(HaveKeyWithValue("cost-center", "1234"))
+			Expect(apierrors.IsNotFound(k8sClient.Get(ctx, nn(name), &hpav2.HorizontalPodAutoscaler{}))).To(BeTrue())
+
+			// Tamper with the labels and annotations, labels of others are kept.
+			hpa.Labels = map[string]string{"cost-center": "9999", "other": "kept"}
+			hpa.Annotations = nil
+			Expect(k8sClient.Update(ctx, hpa)).To(Succeed())
+
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name+"-hpa"), hpa)).To(Succeed())
+			Expect(hpa.Labels).To(Equal(map[string]string{"cost-center": "1234", "other": "kept"}))
+			Expect(hpa.Annotations).To(HaveKeyWithValue("example.com/owner", "team-a"))
+		})
+
+		It("leaves fields changed by other field managers alone and reports the conflict", func() {
+			const name = "test-ownership-conflict"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			maxReplicas := hpa.Spec.MaxReplicas
+			hpa.Spec.MaxReplicas = 99
+			Expect(k8sClient.Update(ctx, hpa, client.FieldOwner("kubectl-edit"))).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			result, err := doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(conflictRequeueInterval))
+			Expect(receivedEvents(recorder)).To(ContainElement(And(HavePrefix("Warning Conflict"), ContainSubstring("kubectl-edit"))))
+
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(99)))
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			condition := meta.FindStatusCondition(cpa.Status.Conditions, typeConflictCraneAutoscaler)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(conflictReasonFieldManagers))
+
+			// Once the change is reverted, the controller manages the HPA again.
+			hpa.Spec.MaxReplicas = maxReplicas
+			Expect(k8sClient.Update(ctx, hpa, client.FieldOwner("kubectl-edit"))).To(Succeed())
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(cpa.Status.Conditions, typeConflictCraneAutoscaler)).To(BeTrue())
+		})
+
+		It("migrates fields written with Update by earlier versions to server-side apply", func() {
+			const name = "test-legacy-field-manager"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Earlier versions updated the HPA without a field manager, so the API server recorded the binary name.
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			maxReplicas := hpa.Spec.MaxReplicas
+			hpa.Spec.MaxReplicas = 99
+			Expect(k8sClient.Update(ctx, hpa, client.FieldOwner(legacyFieldManager))).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			_, err = doReconcileWithRecorder(ctx, name, recorder)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(receivedEvents(recorder)).NotTo(ContainElement(HavePrefix("Warning Conflict")))
+
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.MaxReplicas).To(Equal(maxReplicas))
+			Expect(otherFieldManagers(hpa)).To(BeEmpty())
+		})
+
+		It("keeps fields defaulted by the API server and set by others", func() {
+			const name = "test-foreign-fields"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.Spec.Behavior).NotTo(BeNil(), "the API server defaults the HPA behavior")
+			hpa.Labels = map[string]string{"team": "a"}
+			Expect(k8sClient.Update(ctx, hpa, client.FieldOwner("other-controller"))).To(Succeed())
+			resourceVersion := hpa.ResourceVersion
+
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.ResourceVersion).To(Equal(resourceVersion))
+			Expect(hpa.Labels).To(HaveKeyWithValue("team", "a"))
+		})
+
+		It("corrects VPA UpdateMode drift", func() {
+			const name = "test-vpa-drift"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Set VPA recommendation and HPA status so re-reconcile doesn't panic on nil recommendation.
+			setHPAStatus(ctx, name, 5)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+
+			// VPA should be disabled (Off) since HPA is active.
+			// Manually tamper to Recreate.
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			vpa.Spec.UpdatePolicy.UpdateMode = ptr.To[vpav1.UpdateMode](vpav1.UpdateModeRecreate)
+			Expect(k8sClient.Update(ctx, vpa)).To(Succeed())
+
+			// Reconcile should correct it back to Off.
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeOff))
+		})
+	})
+
+	Context("edge cases", func() {
+		It("handles deleted resource gracefully", func() {
+			result, err := doReconcile(ctx, "nonexistent-resource")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+		})
+
+		It("treats exactly 80% utilization as below threshold (stays on / transitions to VPA)", func() {
+			const name = "test-boundary"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Set HPA at min replicas, VPA exactly at 80% (800m/1000m).
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("800m", "800Mi"),
+			})
+
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// 0.8 is NOT > 0.8, so should switch to VPA.
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			decision := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
+			Expect(decision).NotTo(BeNil())
+			Expect(decision.Reason).To(Equal("VPA"))
+		})
+
+		It("does not panic when VPA has no recommendation and defaults to HPA", func() {
+			const name = "test-nil-recommendation"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			// First reconcile: creates HPA+VPA, defaults to HPA active.
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Set HPA at min replicas but leave VPA recommendation nil (no recommendation yet).
+			setHPAStatus(ctx, name, 2)
+
+			// Second reconcile: VPA has no recommendation. Must not panic and should stay on HPA.
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			decision := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
+			Expect(decision).NotTo(BeNil())
+			Expect(decision.Reason).To(Equal("HPA"))
+		})
+
+		It("does not panic when VPA upper bound is zero and treats utilization as zero", func() {
+			const name = "test-zero-upperbound"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			// First reconcile: creates HPA+VPA, defaults to HPA active.
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Set HPA at min replicas and VPA recommendation with zero upper bounds.
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendationWithUpperBound("500m", "500Mi", "0", "0"),
+			})
+
+			// Reconcile must not panic and should transition to VPA
+			// since zero upper bound means zero utilization (below threshold).
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			decision := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
+			Expect(decision).NotTo(BeNil())
+			Expect(decision.Reason).To(Equal("VPA"))
+		})
+
+		It("handles partial zero upper bound (only CPU zero) without panic", func() {
+			const name = "test-partial-zero-upperbound"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Transition to VPA first.
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendationWithUpperBound("500m", "500Mi", "1000m", "1000Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("VPA"))
+
+			// Now set CPU upper bound to zero but memory above threshold (90%).
+			// Memory utilization alone should trigger the switch to HPA.
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendationWithUpperBound("500m", "900Mi", "0", "1000Mi"),
+			})
+
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			decision := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
+			Expect(decision).NotTo(BeNil())
+			Expect(decision.Reason).To(Equal("HPA"))
+		})
+
+		It("triggers HPA transition when memory exceeds threshold even if CPU is below", func() {
+			const name = "test-mem-threshold"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			// First reconcile: HPA active.
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Transition to VPA first.
+			setHPAStatus(ctx, name, 2)
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "500Mi"),
+			})
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision").Reason).To(Equal("VPA"))
+
+			// Now set CPU below threshold (50%) but memory above threshold (90%).
+			setVPARecommendation(ctx, name, []vpav1.RecommendedContainerResources{
+				vpaContainerRecommendation("500m", "900Mi"),
+			})
+
+			_, err = doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Should switch to HPA because memory utilization exceeds threshold.
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			decision := meta.FindStatusCondition(cpa.Status.Conditions, "ScalingDecision")
+			Expect(decision).NotTo(BeNil())
+			Expect(decision.Reason).To(Equal("HPA"))
+		})
+	})
+
+	Context("owner references", func() {
+		It("sets controller owner references on both HPA and VPA", func() {
+			const name = "test-ownerrefs"
+			defer cleanup(ctx, name)
+
+			cpa := newCranePodAutoscaler(name)
+			Expect(k8sClient.Create(ctx, cpa)).To(Succeed())
+
+			_, err := doReconcile(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Re-fetch the CR to get its UID.
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+
+			hpa := &hpav2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), hpa)).To(Succeed())
+			Expect(hpa.OwnerReferences).To(HaveLen(1))
+			ownerRef := hpa.OwnerReferences[0]
+			Expect(ownerRef.UID).To(Equal(cpa.UID))
+			Expect(*ownerRef.Controller).To(BeTrue())
+			Expect(*ownerRef.BlockOwnerDeletion).To(BeTrue())
+
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), vpa)).To(Succeed())
 			Expect(vpa.OwnerReferences).To(HaveLen(1))
 			ownerRef = vpa.OwnerReferences[0]
 			Expect(ownerRef.UID).To(Equal(cpa.UID))
@@ -638,6 +2584,80 @@ var _ = Describe("CranePodAutoscaler Controller", func() {
 		})
 	})
 
+	Context("status writes", func() {
+		It("writes the status once per reconciliation and only if it changed", func() {
+			const name = "test-status-writes"
+			defer cleanup(ctx, name)
+
+			Expect(k8sClient.Create(ctx, newCranePodAutoscaler(name))).To(Succeed())
+
+			var patches, updates int
+			countingClient := interceptor.NewClient(newWatchClient(), interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					patches++
+					return c.SubResource(subResource).Patch(ctx, obj, patch, opts...)
+				},
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					updates++
+					return c.SubResource(subResource).Update(ctx, obj, opts...)
+				},
+			})
+			r := &CranePodAutoscalerReconciler{Client: countingClient, Scheme: k8sClient.Scheme()}
+			req := reconcile.Request{NamespacedName: nn(name)}
+
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(patches).To(Equal(1))
+			Expect(updates).To(BeZero())
+
+			cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(cpa.Status.Conditions, "Available")).To(BeTrue())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(patches).To(Equal(1))
+		})
+
+		It("recomputes the status instead of patching it onto a newer version", func() {
+			const name = "test-status-conflict"
+			defer cleanup(ctx, name)
+
+			Expect(k8sClient.Create(ctx, newCranePodAutoscaler(name))).To(Succeed())
+
+			var attempts int
+			conflictingClient := interceptor.NewClient(newWatchClient(), interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					attempts++
+					if attempts == 1 {
+						// Change the spec concurrently, so that the status computed from the outdated version conflicts.
+						concurrent := &autoscalingv1alpha1.CranePodAutoscaler{}
+						Expect(c.Get(ctx, nn(name), concurrent)).To(Succeed())
+						concurrent.Spec.Mode = autoscalingv1alpha1.ModePinnedVPA
+						Expect(c.Update(ctx, concurrent)).To(Succeed())
+					}
+					return c.SubResource(subResource).Patch(ctx, obj, patch, opts...)
+				},
+			})
+			recorder := record.NewFakeRecorder(10)
+			r := &CranePodAutoscalerReconciler{Client: conflictingClient, Scheme: k8sClient.Scheme(), Recorder: recorder}
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn(name)})
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+			Expect(attempts).To(Equal(1))
+			cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(BeEmpty())
+
+			// The requeued reconciliation decides on the latest spec.
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: nn(name)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn(name), cpa)).To(Succeed())
+			Expect(cpa.Status.ActiveAutoscaler).To(Equal("VPA"))
+			Expect(cpa.Status.Override).NotTo(BeNil())
+		})
+	})
+
 	Context("idempotent re-reconciliation", func() {
 		It("does not error on repeated reconciles without state change", func() {
 			const name = "test-idempotent"
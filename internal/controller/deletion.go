@@ -0,0 +1,198 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	hpav2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// deletionPolicyFinalizer makes sure the deletion policy is enforced before a cranepodautoscaler is removed.
+// It is only added for policies other than Delete, which is handled by garbage collection of the owned autoscalers.
+const deletionPolicyFinalizer = "autoscaling.phihos.github.io/deletion-policy"
+
+// Reasons of the events emitted when enforcing the deletion policy
+const (
+	eventReasonOrphanedHPA      = "OrphanedHPA"
+	eventReasonRestoredReplicas = "RestoredReplicas"
+)
+
+// reconcileFinalizer adds the finalizer if the deletion policy requires one and removes it otherwise.
+func (r *CranePodAutoscalerReconciler) reconcileFinalizer(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) error {
+	policy := craneAutoscaler.Spec.DeletionPolicy
+	needsFinalizer := policy != "" && policy != autoscalingv1alpha1.DeletionPolicyDelete
+	var changed bool
+	if needsFinalizer {
+		changed = controllerutil.AddFinalizer(craneAutoscaler, deletionPolicyFinalizer)
+	} else {
+		changed = controllerutil.RemoveFinalizer(craneAutoscaler, deletionPolicyFinalizer)
+	}
+	if !changed {
+		return nil
+	}
+	if err := r.Update(ctx, craneAutoscaler); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update finalizers of cranepodautoscaler")
+		recordReconcileError(reconcilePhaseUpdate)
+		r.recordUpdateConflict(craneAutoscaler, err, "finalizers")
+		return err
+	}
+	return nil
+}
+
+// finalize enforces the deletion policy of a cranepodautoscaler that is being deleted and removes the finalizer afterwards.
+func (r *CranePodAutoscalerReconciler) finalize(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(craneAutoscaler, deletionPolicyFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Enforcing deletion policy", "policy", craneAutoscaler.Spec.DeletionPolicy)
+	switch craneAutoscaler.Spec.DeletionPolicy {
+	case autoscalingv1alpha1.DeletionPolicyOrphanActiveHPA:
+		if err := r.orphanActiveHPA(ctx, craneAutoscaler); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.deleteVPA(ctx, craneAutoscaler); err != nil {
+			return ctrl.Result{}, err
+		}
+	case autoscalingv1alpha1.DeletionPolicyRestoreReplicas:
+		// Delete both autoscalers first so that neither of them interferes with the restored replicas.
+		if err := r.deleteHPA(ctx, craneAutoscaler); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.deleteVPA(ctx, craneAutoscaler); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.restoreReplicas(ctx, craneAutoscaler); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(craneAutoscaler, deletionPolicyFinalizer)
+	if err := r.Update(ctx, craneAutoscaler); err != nil {
+		logger.Error(err, "Failed to remove finalizer from cranepodautoscaler")
+		recordReconcileError(reconcilePhaseUpdate)
+		r.recordUpdateConflict(craneAutoscaler, err, "finalizers")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// orphanActiveHPA enables the HPA and releases it from the cranepodautoscaler so that it is not garbage collected.
+func (r *CranePodAutoscalerReconciler) orphanActiveHPA(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) error {
+	logger := log.FromContext(ctx)
+	hpa := &hpav2.HorizontalPodAutoscaler{}
+	if err := r.Get(ctx, types.NamespacedName{Name: craneAutoscaler.HPAName(), Namespace: craneAutoscaler.Namespace}, hpa); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		recordReconcileError(reconcilePhaseGet)
+		return err
+	}
+
+	if !metav1.IsControlledBy(hpa, craneAutoscaler) {
+		return nil
+	}
+
+	hpa.Spec = craneAutoscaler.GenerateEnabledHPA().Spec
+	var ownerReferences []metav1.OwnerReference
+	for _, ref := range hpa.OwnerReferences {
+		if ref.UID != craneAutoscaler.UID {
+			ownerReferences = append(ownerReferences, ref)
+		}
+	}
+	hpa.OwnerReferences = ownerReferences
+	if err := r.Update(ctx, hpa); err != nil {
+		logger.Error(err, "Failed to orphan resource", "resource.Kind", refHPA,
+			"resource.Namespace", hpa.Namespace, "resource.Name", hpa.Name)
+		recordReconcileError(reconcilePhaseUpdate)
+		r.recordUpdateConflict(craneAutoscaler, err, refHPA+" "+hpa.Name)
+		return err
+	}
+	r.event(craneAutoscaler, corev1.EventTypeNormal, eventReasonOrphanedHPA, "Kept HPA %s enabled after deletion", hpa.Name)
+	return nil
+}
+
+// restoreReplicas scales the target to the configured number of replicas. A target that no longer exists is skipped.
+func (r *CranePodAutoscalerReconciler) restoreReplicas(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) error {
+	logger := log.FromContext(ctx)
+	if craneAutoscaler.Spec.RestoreReplicas == nil {
+		return nil
+	}
+	replicas := *craneAutoscaler.Spec.RestoreReplicas
+	if err := r.scaleTarget(ctx, craneAutoscaler, replicas); err != nil {
+		var resolutionErr *targetResolutionError
+		if errors.As(err, &resolutionErr) {
+			logger.Info("Target could not be resolved, skipping restoring replicas", "message", resolutionErr.message)
+			return nil
+		}
+		logger.Error(err, "Failed to restore replicas of target")
+		recordReconcileError(reconcilePhaseUpdate)
+		return err
+	}
+	r.event(craneAutoscaler, corev1.EventTypeNormal, eventReasonRestoredReplicas, "Scaled %s %s to %d replicas",
+		craneAutoscaler.Spec.HPA.ScaleTargetRef.Kind, craneAutoscaler.Spec.HPA.ScaleTargetRef.Name, replicas)
+	return nil
+}
+
+func (r *CranePodAutoscalerReconciler) deleteHPA(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) error {
+	hpa := &hpav2.HorizontalPodAutoscaler{}
+	hpa.Name = craneAutoscaler.HPAName()
+	hpa.Namespace = craneAutoscaler.Namespace
+	return r.deleteAutoscaler(ctx, craneAutoscaler, refHPA, hpa)
+}
+
+func (r *CranePodAutoscalerReconciler) deleteVPA(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) error {
+	vpa := &vpav1.VerticalPodAutoscaler{}
+	vpa.Name = craneAutoscaler.VPAName()
+	vpa.Namespace = craneAutoscaler.Namespace
+	return r.deleteAutoscaler(ctx, craneAutoscaler, refVPA, vpa)
+}
+
+// deleteAutoscaler deletes an HPA or VPA if it is controlled by the cranepodautoscaler.
+func (r *CranePodAutoscalerReconciler) deleteAutoscaler(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, resourceKind string, obj client.Object) error {
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		recordReconcileError(reconcilePhaseGet)
+		return err
+	}
+	if !metav1.IsControlledBy(obj, craneAutoscaler) {
+		return nil
+	}
+	if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		log.FromContext(ctx).Error(err, "Failed to delete resource", "resource.Kind", resourceKind,
+			"resource.Namespace", obj.GetNamespace(), "resource.Name", obj.GetName())
+		recordReconcileError(reconcilePhaseUpdate)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// typeProgressingCraneAutoscaler is set while a handoff from HPA to VPA is in progress.
+const typeProgressingCraneAutoscaler = "Progressing"
+
+// Reasons of the Progressing condition and the handoff events
+const (
+	handoffReasonSteppingDown         = "SteppingDown"
+	handoffReasonWaitingForPodUpdates = "WaitingForPodUpdates"
+	handoffReasonComplete             = "HandoffComplete"
+	handoffReasonTimedOut             = "HandoffTimedOut"
+	handoffReasonAborted              = "HandoffAborted"
+	eventReasonHandoffStarted         = "HandoffStarted"
+)
+
+// handoffPodCheckInterval is the interval after which the pods are checked again while waiting for the VPA to update them.
+const handoffPodCheckInterval = 15 * time.Second
+
+// handoffToVPA advances the handoff from HPA to VPA and reports whether it is complete, so that VPA mode can be declared.
+// While the handoff is in progress the HPA stays active with its max replicas limited to status.handoff.maxReplicas,
+// and the VPA is enabled once the HPA reached its min replicas. The returned duration is the time until the next step.
+func (r *CranePodAutoscalerReconciler) handoffToVPA(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, currentReplicas int32, vpa *vpav1.VerticalPodAutoscaler) (bool, time.Duration, error) {
+	logger := log.FromContext(ctx)
+	rules := craneAutoscaler.Spec.Behavior.SwitchToVPA.GetHandoff()
+	if rules == nil || (rules.MaxReplicasStep == nil && rules.UpdatedPodsPercent == nil) {
+		craneAutoscaler.Status.Handoff = nil
+		return true, 0, nil
+	}
+
+	now := metav1.NewTime(r.now())
+	minReplicas := ptr.Deref(craneAutoscaler.Spec.HPA.MinReplicas, 1)
+	handoff := craneAutoscaler.Status.Handoff
+	if handoff == nil {
+		handoff = &autoscalingv1alpha1.HandoffStatus{Phase: autoscalingv1alpha1.HandoffPhaseWaitingForPodUpdates, StartTime: now, MaxReplicas: minReplicas}
+		if rules.MaxReplicasStep != nil && currentReplicas > minReplicas {
+			handoff.Phase = autoscalingv1alpha1.HandoffPhaseSteppingDown
+			handoff.MaxReplicas = currentReplicas
+			handoff.LastStepTime = &now
+		}
+		craneAutoscaler.Status.Handoff = handoff
+		logger.Info("Starting handoff to VPA", "phase", handoff.Phase, "maxReplicas", handoff.MaxReplicas)
+		r.event(craneAutoscaler, corev1.EventTypeNormal, eventReasonHandoffStarted, "Starting handoff from HPA to VPA at %d replicas", currentReplicas)
+	}
+
+	if timeout := rules.Timeout(); now.Sub(handoff.StartTime.Time) >= timeout {
+		logger.Info("Handoff to VPA timed out, declaring VPA mode anyway", "timeout", timeout)
+		r.event(craneAutoscaler, corev1.EventTypeWarning, handoffReasonTimedOut, "Handoff to VPA did not complete within %s", timeout)
+		r.endHandoff(craneAutoscaler, handoffReasonTimedOut, fmt.Sprintf("Handoff to VPA timed out after %s", timeout))
+		return true, 0, nil
+	}
+
+	if handoff.Phase == autoscalingv1alpha1.HandoffPhaseSteppingDown {
+		// Each step, including the last one, settles for a whole interval before the next phase begins.
+		interval := rules.StepInterval()
+		if next := handoff.LastStepTime.Add(interval); now.Time.Before(next) {
+			setHandoffProgress(craneAutoscaler, handoffReasonSteppingDown, fmt.Sprintf("Handing off to VPA: HPA max replicas are limited to %d, min replicas are %d", handoff.MaxReplicas, minReplicas))
+			return false, next.Sub(now.Time), nil
+		}
+		if handoff.MaxReplicas > minReplicas {
+			handoff.MaxReplicas = max(minReplicas, handoff.MaxReplicas-*rules.MaxReplicasStep)
+			handoff.LastStepTime = &now
+			logger.Info("Stepping down HPA max replicas", "maxReplicas", handoff.MaxReplicas)
+			setHandoffProgress(craneAutoscaler, handoffReasonSteppingDown, fmt.Sprintf("Handing off to VPA: HPA max replicas are limited to %d, min replicas are %d", handoff.MaxReplicas, minReplicas))
+			return false, interval, nil
+		}
+		handoff.Phase = autoscalingv1alpha1.HandoffPhaseWaitingForPodUpdates
+	}
+
+	if rules.UpdatedPodsPercent != nil {
+		pods, err := r.countUpdatedPods(ctx, craneAutoscaler, vpa)
+		if err != nil {
+			logger.Error(err, "Failed to count the pods updated by the VPA")
+			recordReconcileError(reconcilePhaseGet)
+			return false, 0, err
+		}
+		handoff.UpdatedPods = pods.updated
+		handoff.TotalPods = pods.total
+		if pods.total == 0 || pods.updated*100 < *rules.UpdatedPodsPercent*pods.total {
+			setHandoffProgress(craneAutoscaler, handoffReasonWaitingForPodUpdates, fmt.Sprintf("Handing off to VPA: %s, waiting for %d%%",
+				pods, *rules.UpdatedPodsPercent))
+			return false, pods.checkInterval(), nil
+		}
+	}
+
+	logger.Info("Handoff to VPA complete", "duration", now.Sub(handoff.StartTime.Time))
+	r.endHandoff(craneAutoscaler, handoffReasonComplete, "Handoff to VPA completed")
+	return true, 0, nil
+}
+
+// abortHandoff ends a handoff that is in progress, because the switch to VPA no longer takes place.
+func (r *CranePodAutoscalerReconciler) abortHandoff(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, reason string) {
+	if craneAutoscaler.Status.Handoff == nil {
+		return
+	}
+	log.FromContext(ctx).Info("Aborting handoff to VPA", "reason", reason)
+	r.event(craneAutoscaler, corev1.EventTypeNormal, handoffReasonAborted, "Aborted handoff to VPA: %s", reason)
+	r.endHandoff(craneAutoscaler, handoffReasonAborted, fmt.Sprintf("Handoff to VPA was aborted: %s", reason))
+}
+
+// endHandoff clears the handoff from the status and records how it ended.
+func (r *CranePodAutoscalerReconciler) endHandoff(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, reason string, message string) {
+	craneAutoscaler.Status.Handoff = nil
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeProgressingCraneAutoscaler,
+		Status: metav1.ConditionFalse, Reason: reason, Message: message})
+}
+
+// setHandoffProgress records a handoff that is in progress in the Progressing condition.
+func setHandoffProgress(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, reason string, message string) {
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeProgressingCraneAutoscaler,
+		Status: metav1.ConditionTrue, Reason: reason, Message: message})
+}
+
+// handoffEnablesVPA reports whether the VPA is enabled for a handoff that is in progress.
+func handoffEnablesVPA(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) bool {
+	handoff := craneAutoscaler.Status.Handoff
+	return handoff != nil && handoff.Phase == autoscalingv1alpha1.HandoffPhaseWaitingForPodUpdates
+}
+
+// podUpdates counts the running pods of the target by whether their requests lie within a recommendation.
+type podUpdates struct {
+	// updated is the number of pods whose requests lie within the recommendation.
+	updated int32
+	// resizing is the number of pods that are not updated yet, but are being resized in place to the recommendation.
+	resizing int32
+	// total is the number of all running pods.
+	total int32
+}
+
+func (p podUpdates) String() string {
+	if p.resizing > 0 {
+		return fmt.Sprintf("%d of %d pods have the recommended requests and %d are being resized in place", p.updated, p.total, p.resizing)
+	}
+	return fmt.Sprintf("%d of %d pods have the recommended requests", p.updated, p.total)
+}
+
+// checkInterval returns the interval after which the pods are checked again while waiting for them to be updated.
+func (p podUpdates) checkInterval() time.Duration {
+	if p.resizing > 0 {
+		return inPlaceHandoffPodCheckInterval
+	}
+	return handoffPodCheckInterval
+}
+
+// countUpdatedPods counts the pods of the target by whether their requests lie within the bounds recommended by the VPA.
+// The updated pods are the ones the VPA updater leaves alone.
+func (r *CranePodAutoscalerReconciler) countUpdatedPods(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, vpa *vpav1.VerticalPodAutoscaler) (podUpdates, error) {
+	var recommendations []vpav1.RecommendedContainerResources
+	if vpa.Status.Recommendation != nil {
+		recommendations = vpa.Status.Recommendation.ContainerRecommendations
+	}
+	return r.countPodsWithin(ctx, craneAutoscaler, recommendations)
+}
+
+// countPodsWithin counts the running pods of the target by whether their requests lie within the given recommendations.
+func (r *CranePodAutoscalerReconciler) countPodsWithin(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, recommendations []vpav1.RecommendedContainerResources) (podUpdates, error) {
+	_, scale, err := r.getTargetScale(ctx, craneAutoscaler)
+	if err != nil {
+		return podUpdates{}, err
+	}
+	selectorString, _, err := unstructured.NestedString(scale.Object, "status", "selector")
+	if err != nil {
+		return podUpdates{}, err
+	}
+	if selectorString == "" {
+		return podUpdates{}, fmt.Errorf("scale subresource of target %s reports no selector", craneAutoscaler.Spec.HPA.ScaleTargetRef.Name)
+	}
+	selector, err := labels.Parse(selectorString)
+	if err != nil {
+		return podUpdates{}, err
+	}
+	pods := &corev1.PodList{}
+	if err := r.podReader().List(ctx, pods, client.InNamespace(craneAutoscaler.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return podUpdates{}, err
+	}
+
+	resources := craneAutoscaler.VPAControlledResources()
+	var counts podUpdates
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !pod.DeletionTimestamp.IsZero() || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		counts.total++
+		if podWithinRecommendation(pod, recommendations, resources) {
+			counts.updated++
+		} else if podResizingInPlace(pod, recommendations, resources) {
+			counts.resizing++
+		}
+	}
+	return counts, nil
+}
+
+// podReader returns the reader the pods of the target are listed with.
+func (r *CranePodAutoscalerReconciler) podReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// podWithinRecommendation reports whether the requests of the given resources of all containers of the pod
+// lie within the bounds recommended by the VPA. Containers without a recommendation are ignored.
+// The requests reported in the container status take precedence over the pod spec, so that a pod resized in place
+// only counts as updated once the kubelet actually applied the resize.
+func podWithinRecommendation(pod *corev1.Pod, recommendations []vpav1.RecommendedContainerResources, resources []corev1.ResourceName) bool {
+	return requestsWithinRecommendation(pod, recommendations, resources, func(container *corev1.Container) corev1.ResourceList {
+		if status := containerStatus(pod, container.Name); status != nil && status.Resources != nil {
+			return status.Resources.Requests
+		}
+		return container.Resources.Requests
+	})
+}
+
+// requestsWithinRecommendation reports whether the requests of the given resources of all containers of the pod,
+// as returned by requestsOf, lie within the bounds recommended by the VPA. Containers without a recommendation are ignored.
+func requestsWithinRecommendation(pod *corev1.Pod, recommendations []vpav1.RecommendedContainerResources, resources []corev1.ResourceName,
+	requestsOf func(container *corev1.Container) corev1.ResourceList) bool {
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		requests := requestsOf(container)
+		index := slices.IndexFunc(recommendations, func(recommendation vpav1.RecommendedContainerResources) bool {
+			return recommendation.ContainerName == container.Name
+		})
+		if index < 0 {
+			continue
+		}
+		recommendation := recommendations[index]
+		for _, resource := range resources {
+			target, ok := recommendation.Target[resource]
+			if !ok {
+				continue
+			}
+			request, ok := requests[resource]
+			if !ok {
+				return false
+			}
+			lowerBound, ok := recommendation.LowerBound[resource]
+			if !ok {
+				lowerBound = target
+			}
+			upperBound, ok := recommendation.UpperBound[resource]
+			if !ok {
+				upperBound = target
+			}
+			if request.Cmp(lowerBound) < 0 || request.Cmp(upperBound) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// containerStatus returns the status of the container with the given name, or nil if the kubelet did not report it yet.
+func containerStatus(pod *corev1.Pod, name string) *corev1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == name {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"math"
+
+	hpav2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// defaultHPAMetrics are the metrics of an HPA that does not configure any, i.e. a CPU utilization of 80%.
+var defaultHPAMetrics = []hpav2.MetricSpec{{
+	Type: hpav2.ResourceMetricSourceType,
+	Resource: &hpav2.ResourceMetricSource{
+		Name:   corev1.ResourceCPU,
+		Target: hpav2.MetricTarget{Type: hpav2.UtilizationMetricType, AverageUtilization: ptr.To[int32](80)},
+	},
+}}
+
+// observeHPAMetrics returns the current values of the HPA metrics relative to their targets
+// and the names of the metrics the HPA does not report a current value for.
+func observeHPAMetrics(hpa *hpav2.HorizontalPodAutoscaler) ([]autoscalingv1alpha1.HPAMetricStatus, []string) {
+	specs := hpa.Spec.Metrics
+	if len(specs) == 0 {
+		specs = defaultHPAMetrics
+	}
+	var observed []autoscalingv1alpha1.HPAMetricStatus
+	var missing []string
+	for _, spec := range specs {
+		name, target := specMetric(spec)
+		current, ok := currentMetric(hpa.Status.CurrentMetrics, spec.Type, name)
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		metric, ok := compareToTarget(target, current)
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		metric.Type = spec.Type
+		metric.Name = name
+		observed = append(observed, metric)
+	}
+	return observed, missing
+}
+
+// specMetric returns the name and the target of a metric of the HPA spec.
+func specMetric(spec hpav2.MetricSpec) (string, hpav2.MetricTarget) {
+	switch {
+	case spec.Resource != nil:
+		return string(spec.Resource.Name), spec.Resource.Target
+	case spec.ContainerResource != nil:
+		return spec.ContainerResource.Container + "/" + string(spec.ContainerResource.Name), spec.ContainerResource.Target
+	case spec.Pods != nil:
+		return spec.Pods.Metric.Name, spec.Pods.Target
+	case spec.Object != nil:
+		return spec.Object.Metric.Name, spec.Object.Target
+	case spec.External != nil:
+		return spec.External.Metric.Name, spec.External.Target
+	}
+	return string(spec.Type), hpav2.MetricTarget{}
+}
+
+// currentMetric returns the current value of the metric with the given type and name from the HPA status.
+func currentMetric(statuses []hpav2.MetricStatus, metricType hpav2.MetricSourceType, name string) (hpav2.MetricValueStatus, bool) {
+	for _, status := range statuses {
+		if status.Type != metricType {
+			continue
+		}
+		switch {
+		case status.Resource != nil && string(status.Resource.Name) == name:
+			return status.Resource.Current, true
+		case status.ContainerResource != nil && status.ContainerResource.Container+"/"+string(status.ContainerResource.Name) == name:
+			return status.ContainerResource.Current, true
+		case status.Pods != nil && status.Pods.Metric.Name == name:
+			return status.Pods.Current, true
+		case status.Object != nil && status.Object.Metric.Name == name:
+			return status.Object.Current, true
+		case status.External != nil && status.External.Metric.Name == name:
+			return status.External.Current, true
+		}
+	}
+	return hpav2.MetricValueStatus{}, false
+}
+
+// compareToTarget returns the current value of a metric relative to its target.
+// It reports false if the current value is not comparable to the target.
+func compareToTarget(target hpav2.MetricTarget, current hpav2.MetricValueStatus) (autoscalingv1alpha1.HPAMetricStatus, bool) {
+	switch target.Type {
+	case hpav2.UtilizationMetricType:
+		if target.AverageUtilization == nil || *target.AverageUtilization == 0 || current.AverageUtilization == nil {
+			return autoscalingv1alpha1.HPAMetricStatus{}, false
+		}
+		return autoscalingv1alpha1.HPAMetricStatus{
+			Current:         fmt.Sprintf("%d%%", *current.AverageUtilization),
+			Target:          fmt.Sprintf("%d%%", *target.AverageUtilization),
+			PercentOfTarget: percentOf(float64(*current.AverageUtilization), float64(*target.AverageUtilization)),
+		}, true
+	case hpav2.AverageValueMetricType:
+		if target.AverageValue == nil || target.AverageValue.IsZero() || current.AverageValue == nil {
+			return autoscalingv1alpha1.HPAMetricStatus{}, false
+		}
+		return autoscalingv1alpha1.HPAMetricStatus{
+			Current:         current.AverageValue.String(),
+			Target:          target.AverageValue.String(),
+			PercentOfTarget: percentOf(current.AverageValue.AsApproximateFloat64(), target.AverageValue.AsApproximateFloat64()),
+		}, true
+	case hpav2.ValueMetricType:
+		if target.Value == nil || target.Value.IsZero() || current.Value == nil {
+			return autoscalingv1alpha1.HPAMetricStatus{}, false
+		}
+		return autoscalingv1alpha1.HPAMetricStatus{
+			Current:         current.Value.String(),
+			Target:          target.Value.String(),
+			PercentOfTarget: percentOf(current.Value.AsApproximateFloat64(), target.Value.AsApproximateFloat64()),
+		}, true
+	}
+	return autoscalingv1alpha1.HPAMetricStatus{}, false
+}
+
+func percentOf(current float64, target float64) int32 {
+	return int32(math.Round(current / target * 100))
+}
+
+// hpaMetricsHoldBackSwitch returns why the switch to VPA is held back by the HPA metrics,
+// or an empty string if all of them are at or below spec.behavior.switchToVPA.hpaMetricThresholdPercent of their targets.
+func hpaMetricsHoldBackSwitch(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, hpa *hpav2.HorizontalPodAutoscaler) string {
+	switchToVPA := craneAutoscaler.Spec.Behavior.SwitchToVPA
+	if switchToVPA == nil || switchToVPA.HPAMetricThresholdPercent == nil {
+		return ""
+	}
+	threshold := *switchToVPA.HPAMetricThresholdPercent
+	observed, missing := observeHPAMetrics(hpa)
+	if len(missing) > 0 {
+		return fmt.Sprintf("HPA reports no current value for metric %s", missing[0])
+	}
+	for _, metric := range observed {
+		if metric.PercentOfTarget > threshold {
+			return fmt.Sprintf("HPA metric %s at %d%% of its target %s exceeds %d%%", metric.Name, metric.PercentOfTarget, metric.Target, threshold)
+		}
+	}
+	return ""
+}
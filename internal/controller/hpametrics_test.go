@@ -0,0 +1,96 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	hpav2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+var _ = Describe("HPA metrics", func() {
+	// cpuUtilization returns the status of an HPA CPU utilization metric.
+	cpuUtilization := func(percent int32) hpav2.MetricStatus {
+		return hpav2.MetricStatus{Type: hpav2.ResourceMetricSourceType, Resource: &hpav2.ResourceMetricStatus{
+			Name:    corev1.ResourceCPU,
+			Current: hpav2.MetricValueStatus{AverageUtilization: ptr.To(percent)},
+		}}
+	}
+
+	It("observes the current values of the HPA metrics relative to their targets", func() {
+		hpa := &hpav2.HorizontalPodAutoscaler{
+			Spec: hpav2.HorizontalPodAutoscalerSpec{Metrics: []hpav2.MetricSpec{
+				{Type: hpav2.ResourceMetricSourceType, Resource: &hpav2.ResourceMetricSource{
+					Name:   corev1.ResourceCPU,
+					Target: hpav2.MetricTarget{Type: hpav2.UtilizationMetricType, AverageUtilization: ptr.To[int32](80)},
+				}},
+				{Type: hpav2.PodsMetricSourceType, Pods: &hpav2.PodsMetricSource{
+					Metric: hpav2.MetricIdentifier{Name: "requests_per_second"},
+					Target: hpav2.MetricTarget{Type: hpav2.AverageValueMetricType, AverageValue: ptr.To(resource.MustParse("100"))},
+				}},
+				{Type: hpav2.ExternalMetricSourceType, External: &hpav2.ExternalMetricSource{
+					Metric: hpav2.MetricIdentifier{Name: "queue_length"},
+					Target: hpav2.MetricTarget{Type: hpav2.ValueMetricType, Value: ptr.To(resource.MustParse("30"))},
+				}},
+			}},
+			Status: hpav2.HorizontalPodAutoscalerStatus{CurrentMetrics: []hpav2.MetricStatus{
+				{Type: hpav2.PodsMetricSourceType, Pods: &hpav2.PodsMetricStatus{
+					Metric:  hpav2.MetricIdentifier{Name: "requests_per_second"},
+					Current: hpav2.MetricValueStatus{AverageValue: ptr.To(resource.MustParse("25"))},
+				}},
+				cpuUtilization(60),
+			}},
+		}
+		observed, missing := observeHPAMetrics(hpa)
+		Expect(observed).To(Equal([]autoscalingv1alpha1.HPAMetricStatus{
+			{Type: hpav2.ResourceMetricSourceType, Name: "cpu", Current: "60%", Target: "80%", PercentOfTarget: 75},
+			{Type: hpav2.PodsMetricSourceType, Name: "requests_per_second", Current: "25", Target: "100", PercentOfTarget: 25},
+		}))
+		Expect(missing).To(Equal([]string{"queue_length"}))
+	})
+
+	It("defaults to a CPU utilization target of 80% if the HPA configures no metrics", func() {
+		hpa := &hpav2.HorizontalPodAutoscaler{Status: hpav2.HorizontalPodAutoscalerStatus{
+			CurrentMetrics: []hpav2.MetricStatus{cpuUtilization(40)},
+		}}
+		observed, missing := observeHPAMetrics(hpa)
+		Expect(observed).To(Equal([]autoscalingv1alpha1.HPAMetricStatus{
+			{Type: hpav2.ResourceMetricSourceType, Name: "cpu", Current: "40%", Target: "80%", PercentOfTarget: 50},
+		}))
+		Expect(missing).To(BeEmpty())
+	})
+
+	It("holds back the switch to VPA while a metric is above the threshold or unknown", func() {
+		cpa := newCranePodAutoscaler("test-hpa-metrics")
+		hpa := &hpav2.HorizontalPodAutoscaler{}
+		Expect(hpaMetricsHoldBackSwitch(cpa, hpa)).To(BeEmpty())
+
+		cpa.Spec.Behavior.SwitchToVPA = &autoscalingv1alpha1.ModeSwitchRules{HPAMetricThresholdPercent: ptr.To[int32](50)}
+		Expect(hpaMetricsHoldBackSwitch(cpa, hpa)).To(Equal("HPA reports no current value for metric cpu"))
+
+		hpa.Status.CurrentMetrics = []hpav2.MetricStatus{cpuUtilization(60)}
+		Expect(hpaMetricsHoldBackSwitch(cpa, hpa)).To(Equal("HPA metric cpu at 75% of its target 80% exceeds 50%"))
+
+		hpa.Status.CurrentMetrics = []hpav2.MetricStatus{cpuUtilization(40)}
+		Expect(hpaMetricsHoldBackSwitch(cpa, hpa)).To(BeEmpty())
+	})
+})
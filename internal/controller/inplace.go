@@ -0,0 +1,114 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// podResizeSubresource is the subresource the VPA updater resizes pods in place with.
+const podResizeSubresource = "pods/resize"
+
+// typeResizingInPlaceCraneAutoscaler reports whether the VPA resizes pods in place as requested in spec.vpa.
+const typeResizingInPlaceCraneAutoscaler = "ResizingInPlace"
+
+// Reasons of the ResizingInPlace condition and the event emitted when the VPA falls back from InPlaceOrRecreate to Recreate
+const (
+	inPlaceResizeReasonAvailable        = "InPlaceResizeAvailable"
+	eventReasonInPlaceResizeUnavailable = "InPlaceResizeUnavailable"
+)
+
+// inPlaceHandoffPodCheckInterval replaces handoffPodCheckInterval while pods are being resized in place,
+// as a resize lands within seconds while an eviction waits for the replacement pod to be scheduled and started.
+const inPlaceHandoffPodCheckInterval = 5 * time.Second
+
+// InPlacePodResizeAvailable reports whether the API server serves the pods/resize subresource,
+// which the VPA needs to apply recommendations in InPlaceOrRecreate mode.
+func InPlacePodResizeAvailable(client discovery.DiscoveryInterface) (bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(corev1.SchemeGroupVersion.String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == podResizeSubresource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fallBackFromInPlaceResize switches a VPA in InPlaceOrRecreate mode to Recreate if the cluster cannot resize pods in place.
+// The pods are then still updated, by evicting them.
+func (r *CranePodAutoscalerReconciler) fallBackFromInPlaceResize(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, vpa *vpav1.VerticalPodAutoscaler) {
+	r.updateResizingInPlaceCondition(ctx, craneAutoscaler)
+	policy := vpa.Spec.UpdatePolicy
+	if r.InPlacePodResize || policy == nil || ptr.Deref(policy.UpdateMode, "") != vpav1.UpdateModeInPlaceOrRecreate {
+		return
+	}
+	policy.UpdateMode = ptr.To(vpav1.UpdateModeRecreate)
+}
+
+// updateResizingInPlaceCondition records whether the VPA resizes pods in place if spec.vpa requests InPlaceOrRecreate,
+// and removes the condition otherwise. The fallback to Recreate is only reported once, when the condition changes.
+func (r *CranePodAutoscalerReconciler) updateResizingInPlaceCondition(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) {
+	policy := craneAutoscaler.Spec.VPA.UpdatePolicy
+	if policy == nil || ptr.Deref(policy.UpdateMode, "") != vpav1.UpdateModeInPlaceOrRecreate {
+		meta.RemoveStatusCondition(&craneAutoscaler.Status.Conditions, typeResizingInPlaceCraneAutoscaler)
+		return
+	}
+	if r.InPlacePodResize {
+		meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeResizingInPlaceCraneAutoscaler,
+			Status: metav1.ConditionTrue, Reason: inPlaceResizeReasonAvailable, Message: "The VPA resizes pods in place"})
+		return
+	}
+	message := fmt.Sprintf("The cluster cannot resize pods in place, the VPA runs in %s mode instead of %s", vpav1.UpdateModeRecreate, vpav1.UpdateModeInPlaceOrRecreate)
+	if meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeResizingInPlaceCraneAutoscaler,
+		Status: metav1.ConditionFalse, Reason: eventReasonInPlaceResizeUnavailable, Message: message}) {
+		log.FromContext(ctx).Info("In-place pod resize is not available, falling back to Recreate")
+		r.event(craneAutoscaler, corev1.EventTypeWarning, eventReasonInPlaceResizeUnavailable, "%s", message)
+	}
+}
+
+// podResizingInPlace reports whether the pod is being resized in place to the recommendation: its spec already
+// has the recommended requests, but the kubelet did not apply them yet. A resize the node cannot accommodate is not
+// counted, as the VPA evicts the pod instead.
+func podResizingInPlace(pod *corev1.Pod, recommendations []vpav1.RecommendedContainerResources, resources []corev1.ResourceName) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodResizePending && condition.Status == corev1.ConditionTrue && condition.Reason == corev1.PodReasonInfeasible {
+			return false
+		}
+	}
+	return requestsWithinRecommendation(pod, recommendations, resources, func(container *corev1.Container) corev1.ResourceList {
+		return container.Resources.Requests
+	})
+}
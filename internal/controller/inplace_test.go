@@ -0,0 +1,91 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("In-place pod resize", func() {
+	It("is detected through the pods/resize subresource", func() {
+		discovery := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{}}
+		discovery.Resources = []*metav1.APIResourceList{{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods"}, {Name: "pods/status"}},
+		}}
+		available, err := InPlacePodResizeAvailable(discovery)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(available).To(BeFalse())
+
+		discovery.Resources[0].APIResources = append(discovery.Resources[0].APIResources, metav1.APIResource{Name: "pods/resize"})
+		available, err = InPlacePodResizeAvailable(discovery)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(available).To(BeTrue())
+	})
+
+	It("only counts a pod as updated once the kubelet applied the resize", func() {
+		recommendations := []vpav1.RecommendedContainerResources{vpaContainerRecommendation("500m", "500Mi")}
+		resources := []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+		// The VPA resized the pod spec, but the kubelet still runs the container with the old requests.
+		pod := newTargetPod("resized", "500m", "500Mi")
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "app", Resources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("500Mi"),
+			},
+		}}}
+		Expect(podWithinRecommendation(pod, recommendations, resources)).To(BeFalse())
+
+		pod.Status.ContainerStatuses[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("500m")
+		Expect(podWithinRecommendation(pod, recommendations, resources)).To(BeTrue())
+
+		// Without a reported status the pod spec is used.
+		pod.Status.ContainerStatuses = nil
+		Expect(podWithinRecommendation(pod, recommendations, resources)).To(BeTrue())
+	})
+
+	It("counts a pod as being resized once its spec has the recommended requests", func() {
+		recommendations := []vpav1.RecommendedContainerResources{vpaContainerRecommendation("500m", "500Mi")}
+		resources := []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+		pod := newTargetPod("outdated", "100m", "500Mi")
+		Expect(podResizingInPlace(pod, recommendations, resources)).To(BeFalse())
+
+		pod = newTargetPod("resizing", "500m", "500Mi")
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "app", Resources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("500Mi"),
+			},
+		}}}
+		Expect(podResizingInPlace(pod, recommendations, resources)).To(BeTrue())
+
+		// The node cannot accommodate the resize, so the VPA evicts the pod instead.
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type: corev1.PodResizePending, Status: corev1.ConditionTrue, Reason: corev1.PodReasonInfeasible,
+		}}
+		Expect(podResizingInPlace(pod, recommendations, resources)).To(BeFalse())
+	})
+})
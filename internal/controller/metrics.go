@@ -0,0 +1,133 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+const metricsNamespace = "crane_autoscaler"
+
+// Phases of a reconciliation that are distinguished by the reconcile error counter
+const (
+	reconcilePhaseGet    = "get"
+	reconcilePhaseCreate = "create"
+	reconcilePhaseUpdate = "update"
+	reconcilePhaseStatus = "status"
+	reconcilePhaseDecide = "decide"
+)
+
+var (
+	activeModeGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "active_mode",
+		Help:      "Whether the given autoscaler is the active one (1) or not (0) for a cranepodautoscaler.",
+	}, []string{"namespace", "name", "mode"})
+
+	vpaUtilizationRatioGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "vpa_utilization_ratio",
+		Help:      "Utilization of the most critical container resource computed from the VPA recommendation and upper bound.",
+	}, []string{"namespace", "name"})
+
+	thresholdRatioGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "threshold_ratio",
+		Help:      "Configured threshold for switching away from the active autoscaler that applies to the most critical container resource.",
+	}, []string{"namespace", "name"})
+
+	transitionsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "transitions_total",
+		Help:      "Number of switches between the autoscalers of a cranepodautoscaler.",
+	}, []string{"namespace", "name", "direction"})
+
+	modeDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "mode_duration_seconds",
+		Help:      "How long an autoscaler stayed active before the cranepodautoscaler switched to the other one.",
+		// 1 minute up to about 2 days.
+		Buckets: prometheus.ExponentialBuckets(60, 2, 12),
+	}, []string{"mode"})
+
+	reconcileErrorsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of errors during reconciliation of cranepodautoscalers by phase (get, create, update, status, decide).",
+	}, []string{"phase"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		activeModeGauge,
+		vpaUtilizationRatioGauge,
+		thresholdRatioGauge,
+		transitionsCounter,
+		modeDurationHistogram,
+		reconcileErrorsCounter,
+	)
+}
+
+// recordDecisionMetrics updates the gauges describing the current scaling decision of a cranepodautoscaler.
+func recordDecisionMetrics(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, activeAutoscaler string, critical *ResourceUtilization, threshold float32) {
+	for _, mode := range []string{refHPA, refVPA} {
+		value := 0.0
+		if mode == activeAutoscaler || activeAutoscaler == refSplit {
+			value = 1
+		}
+		activeModeGauge.WithLabelValues(craneAutoscaler.Namespace, craneAutoscaler.Name, mode).Set(value)
+	}
+
+	if critical == nil {
+		vpaUtilizationRatioGauge.DeleteLabelValues(craneAutoscaler.Namespace, craneAutoscaler.Name)
+		thresholdRatioGauge.DeleteLabelValues(craneAutoscaler.Namespace, craneAutoscaler.Name)
+		return
+	}
+	vpaUtilizationRatioGauge.WithLabelValues(craneAutoscaler.Namespace, craneAutoscaler.Name).Set(float64(critical.Utilization))
+	thresholdRatioGauge.WithLabelValues(craneAutoscaler.Namespace, craneAutoscaler.Name).Set(float64(threshold))
+}
+
+// recordTransitionMetrics counts a switch between the autoscalers and observes how long the previous one was active.
+// The initial activation is not a switch and therefore not recorded.
+func recordTransitionMetrics(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, from string, to string, activeSince *time.Time, now time.Time) {
+	if from == "" {
+		return
+	}
+	transitionsCounter.WithLabelValues(craneAutoscaler.Namespace, craneAutoscaler.Name, from+"To"+to).Inc()
+	if activeSince != nil {
+		modeDurationHistogram.WithLabelValues(from).Observe(now.Sub(*activeSince).Seconds())
+	}
+}
+
+// recordReconcileError counts an error that occurred in the given phase of a reconciliation.
+func recordReconcileError(phase string) {
+	reconcileErrorsCounter.WithLabelValues(phase).Inc()
+}
+
+// deleteMetrics removes all per-object series of a deleted cranepodautoscaler.
+func deleteMetrics(namespace string, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	activeModeGauge.DeletePartialMatch(labels)
+	vpaUtilizationRatioGauge.DeletePartialMatch(labels)
+	thresholdRatioGauge.DeletePartialMatch(labels)
+	transitionsCounter.DeletePartialMatch(labels)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+var _ = Describe("Metrics", func() {
+	newMetricsAutoscaler := func(name string) *autoscalingv1alpha1.CranePodAutoscaler {
+		return &autoscalingv1alpha1.CranePodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "metrics"}}
+	}
+
+	It("reports the active mode, utilization and threshold", func() {
+		cpa := newMetricsAutoscaler("test-metrics-decision")
+		defer deleteMetrics(cpa.Namespace, cpa.Name)
+
+		critical := &ResourceUtilization{Container: "app", Resource: corev1.ResourceCPU, Utilization: 0.5, VPAToHPAThreshold: 0.8}
+		recordDecisionMetrics(cpa, refVPA, critical, critical.VPAToHPAThreshold)
+		Expect(testutil.ToFloat64(activeModeGauge.WithLabelValues(cpa.Namespace, cpa.Name, refVPA))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(activeModeGauge.WithLabelValues(cpa.Namespace, cpa.Name, refHPA))).To(Equal(0.0))
+		Expect(testutil.ToFloat64(vpaUtilizationRatioGauge.WithLabelValues(cpa.Namespace, cpa.Name))).To(BeNumerically("~", 0.5, 1e-6))
+		Expect(testutil.ToFloat64(thresholdRatioGauge.WithLabelValues(cpa.Namespace, cpa.Name))).To(BeNumerically("~", 0.8, 1e-6))
+
+		// Without a recommendation there is no utilization to report.
+		recordDecisionMetrics(cpa, refHPA, nil, 0)
+		Expect(testutil.ToFloat64(activeModeGauge.WithLabelValues(cpa.Namespace, cpa.Name, refHPA))).To(Equal(1.0))
+		Expect(vpaUtilizationRatioGauge.DeleteLabelValues(cpa.Namespace, cpa.Name)).To(BeFalse())
+		Expect(thresholdRatioGauge.DeleteLabelValues(cpa.Namespace, cpa.Name)).To(BeFalse())
+	})
+
+	It("counts switches by direction and observes the mode duration", func() {
+		cpa := newMetricsAutoscaler("test-metrics-transitions")
+		defer deleteMetrics(cpa.Namespace, cpa.Name)
+
+		now := time.Now()
+		observationsBefore := modeDurationObservations(refHPA)
+		recordTransitionMetrics(cpa, "", refHPA, nil, now)
+		Expect(transitionsCounter.DeleteLabelValues(cpa.Namespace, cpa.Name, "ToHPA")).To(BeFalse())
+
+		recordTransitionMetrics(cpa, refHPA, refVPA, ptr.To(now.Add(-time.Hour)), now)
+		recordTransitionMetrics(cpa, refVPA, refHPA, ptr.To(now.Add(-time.Minute)), now)
+		recordTransitionMetrics(cpa, refHPA, refVPA, ptr.To(now.Add(-time.Minute)), now)
+		Expect(testutil.ToFloat64(transitionsCounter.WithLabelValues(cpa.Namespace, cpa.Name, "HPAToVPA"))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(transitionsCounter.WithLabelValues(cpa.Namespace, cpa.Name, "VPAToHPA"))).To(Equal(1.0))
+		Expect(modeDurationObservations(refHPA)).To(Equal(observationsBefore + 2))
+
+		deleteMetrics(cpa.Namespace, cpa.Name)
+		Expect(transitionsCounter.DeleteLabelValues(cpa.Namespace, cpa.Name, "HPAToVPA")).To(BeFalse())
+	})
+
+	It("counts reconcile errors by phase", func() {
+		before := testutil.ToFloat64(reconcileErrorsCounter.WithLabelValues(reconcilePhaseUpdate))
+		recordReconcileError(reconcilePhaseUpdate)
+		Expect(testutil.ToFloat64(reconcileErrorsCounter.WithLabelValues(reconcilePhaseUpdate))).To(Equal(before + 1))
+	})
+})
+
+func modeDurationObservations(mode string) uint64 {
+	metric := &dto.Metric{}
+	ExpectWithOffset(1, modeDurationHistogram.WithLabelValues(mode).(prometheus.Histogram).Write(metric)).To(Succeed())
+	return metric.GetHistogram().GetSampleCount()
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// updateOverrideStatus records in the status whether and by whom spec.mode overrides the automatic switching.
+func updateOverrideStatus(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) {
+	mode := craneAutoscaler.Spec.Mode
+	if mode == "" || mode == autoscalingv1alpha1.ModeAuto {
+		craneAutoscaler.Status.Override = nil
+		return
+	}
+	craneAutoscaler.Status.Override = &autoscalingv1alpha1.ModeOverride{Mode: mode, By: modeSetBy(craneAutoscaler)}
+}
+
+// reconcileModeChangedBy removes the mode-changed-by annotation once spec.mode returned to Auto,
+// so that it does not point to an override that no longer applies.
+func (r *CranePodAutoscalerReconciler) reconcileModeChangedBy(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) error {
+	patch := client.MergeFrom(craneAutoscaler.DeepCopy())
+	if !clearModeChangedBy(craneAutoscaler) {
+		return nil
+	}
+	if err := r.Patch(ctx, craneAutoscaler, patch); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to remove the mode-changed-by annotation of cranepodautoscaler")
+		recordReconcileError(reconcilePhaseUpdate)
+		return err
+	}
+	return nil
+}
+
+// clearModeChangedBy removes the mode-changed-by annotation if the override recorded in the status was cleared
+// by returning spec.mode to Auto. An annotation set ahead of a mode change is kept. It returns whether it was removed.
+func clearModeChangedBy(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) bool {
+	mode := craneAutoscaler.Spec.Mode
+	if craneAutoscaler.Status.Override == nil || (mode != "" && mode != autoscalingv1alpha1.ModeAuto) {
+		return false
+	}
+	if _, ok := craneAutoscaler.Annotations[autoscalingv1alpha1.ModeChangedByAnnotation]; !ok {
+		return false
+	}
+	delete(craneAutoscaler.Annotations, autoscalingv1alpha1.ModeChangedByAnnotation)
+	return true
+}
+
+// describeOverride returns a human-readable description of the override recorded in the status.
+func describeOverride(override *autoscalingv1alpha1.ModeOverride) string {
+	if override.By == "" {
+		return fmt.Sprintf("spec.mode is %s", override.Mode)
+	}
+	return fmt.Sprintf("spec.mode is %s, set by %s", override.Mode, override.By)
+}
+
+// pinnedAutoscaler returns the autoscaler a mode pins, or an empty string if the mode does not pin one.
+func pinnedAutoscaler(mode autoscalingv1alpha1.Mode) string {
+	switch mode {
+	case autoscalingv1alpha1.ModePinnedHPA:
+		return refHPA
+	case autoscalingv1alpha1.ModePinnedVPA:
+		return refVPA
+	default:
+		return ""
+	}
+}
+
+// modeSetBy returns who set spec.mode. The mode-changed-by annotation takes precedence over the field manager
+// that most recently set spec.mode according to the managed fields.
+func modeSetBy(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) string {
+	if by := craneAutoscaler.Annotations[autoscalingv1alpha1.ModeChangedByAnnotation]; by != "" {
+		return by
+	}
+
+	var by string
+	var latest *metav1.Time
+	for _, entry := range craneAutoscaler.ManagedFields {
+		if entry.Subresource != "" || !managesSpecMode(entry.FieldsV1) {
+			continue
+		}
+		if by == "" || (entry.Time != nil && (latest == nil || latest.Before(entry.Time))) {
+			by = entry.Manager
+			latest = entry.Time
+		}
+	}
+	return by
+}
+
+// managesSpecMode returns whether the managed fields contain spec.mode.
+func managesSpecMode(fields *metav1.FieldsV1) bool {
+	if fields == nil {
+		return false
+	}
+	var managed map[string]map[string]interface{}
+	if err := json.Unmarshal(fields.Raw, &managed); err != nil {
+		return false
+	}
+	_, ok := managed["f:spec"]["f:mode"]
+	return ok
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+var _ = Describe("Mode overrides", func() {
+	managedFields := func(manager string, at time.Time, fields string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{
+			Manager:    manager,
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			Time:       &metav1.Time{Time: at},
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+		}
+	}
+
+	It("reports the field manager that most recently set spec.mode", func() {
+		now := time.Now()
+		cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+		cpa.Spec.Mode = autoscalingv1alpha1.ModePinnedHPA
+		cpa.ManagedFields = []metav1.ManagedFieldsEntry{
+			managedFields("kubectl-client-side-apply", now.Add(-time.Hour), `{"f:spec":{"f:hpa":{},"f:mode":{}}}`),
+			managedFields("kubectl-patch", now, `{"f:spec":{"f:mode":{}}}`),
+			managedFields("kubectl-edit", now.Add(time.Minute), `{"f:metadata":{"f:labels":{}}}`),
+		}
+
+		updateOverrideStatus(cpa)
+		Expect(cpa.Status.Override).To(Equal(&autoscalingv1alpha1.ModeOverride{Mode: autoscalingv1alpha1.ModePinnedHPA, By: "kubectl-patch"}))
+		Expect(describeOverride(cpa.Status.Override)).To(Equal("spec.mode is PinnedHPA, set by kubectl-patch"))
+	})
+
+	It("prefers the mode-changed-by annotation", func() {
+		cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+		cpa.Spec.Mode = autoscalingv1alpha1.ModeSuspended
+		cpa.Annotations = map[string]string{autoscalingv1alpha1.ModeChangedByAnnotation: "jane"}
+		cpa.ManagedFields = []metav1.ManagedFieldsEntry{
+			managedFields("kubectl-patch", time.Now(), `{"f:spec":{"f:mode":{}}}`),
+		}
+
+		updateOverrideStatus(cpa)
+		Expect(cpa.Status.Override.By).To(Equal("jane"))
+	})
+
+	It("clears the override in automatic mode", func() {
+		cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+		cpa.Status.Override = &autoscalingv1alpha1.ModeOverride{Mode: autoscalingv1alpha1.ModePinnedVPA}
+		updateOverrideStatus(cpa)
+		Expect(cpa.Status.Override).To(BeNil())
+		Expect(pinnedAutoscaler(cpa.Spec.Mode)).To(BeEmpty())
+	})
+
+	It("removes the mode-changed-by annotation once the override is cleared", func() {
+		cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+		cpa.Spec.Mode = autoscalingv1alpha1.ModeAuto
+		cpa.Annotations = map[string]string{autoscalingv1alpha1.ModeChangedByAnnotation: "jane: load test", "team": "checkout"}
+		cpa.Status.Override = &autoscalingv1alpha1.ModeOverride{Mode: autoscalingv1alpha1.ModePinnedHPA, By: "jane: load test"}
+
+		Expect(clearModeChangedBy(cpa)).To(BeTrue())
+		Expect(cpa.Annotations).To(Equal(map[string]string{"team": "checkout"}))
+		Expect(clearModeChangedBy(cpa)).To(BeFalse())
+	})
+
+	It("keeps the mode-changed-by annotation while overriding or ahead of an override", func() {
+		cpa := &autoscalingv1alpha1.CranePodAutoscaler{}
+		cpa.Spec.Mode = autoscalingv1alpha1.ModePinnedVPA
+		cpa.Annotations = map[string]string{autoscalingv1alpha1.ModeChangedByAnnotation: "jane"}
+		cpa.Status.Override = &autoscalingv1alpha1.ModeOverride{Mode: autoscalingv1alpha1.ModePinnedVPA, By: "jane"}
+		Expect(clearModeChangedBy(cpa)).To(BeFalse())
+
+		// Annotated before spec.mode is changed away from Auto.
+		cpa.Spec.Mode = autoscalingv1alpha1.ModeAuto
+		cpa.Status.Override = nil
+		Expect(clearModeChangedBy(cpa)).To(BeFalse())
+		Expect(cpa.Annotations).To(HaveKey(autoscalingv1alpha1.ModeChangedByAnnotation))
+	})
+})
@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	hpav2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// reconcileSplit keeps both autoscalers active in Split mode: the HPA as configured
+// and the VPA restricted to the resources the HPA metrics do not use.
+func (r *CranePodAutoscalerReconciler) reconcileSplit(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, hpa *hpav2.HorizontalPodAutoscaler, vpa *vpav1.VerticalPodAutoscaler) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	currentlyActiveAutoscaler := getCurrentlyActiveAutoscaler(craneAutoscaler)
+	craneAutoscaler.Status.PendingSwitch = nil
+	craneAutoscaler.Status.HPABaseline = nil
+	r.abortHandoff(ctx, craneAutoscaler, "spec.mode is Split")
+
+	vpaResources := craneAutoscaler.SplitVPAControlledResources()
+	logger.Info("HPA and VPA are both active in Split mode", "vpaControlledResources", vpaResources)
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeScalingDecisionCraneAutoscaler, Status: metav1.ConditionTrue, Reason: refSplit,
+		Message: fmt.Sprintf("HPA and VPA are both active, the VPA controls %v; %s", vpaResources, describeOverride(craneAutoscaler.Status.Override))})
+	if currentlyActiveAutoscaler != refSplit {
+		r.recordTransition(craneAutoscaler, currentlyActiveAutoscaler, refSplit, describeOverride(craneAutoscaler.Status.Override), nil)
+	}
+	updateDecisionStatus(craneAutoscaler, refSplit, nil, hpa, vpa)
+
+	if err := r.applyVPA(ctx, craneAutoscaler, vpa, craneAutoscaler.GenerateSplitVPA()); err != nil {
+		logger.Error(err, "Failed to reconcile VPA")
+		return r.handleOwnershipConflict(ctx, craneAutoscaler, err)
+	}
+	if err := r.reconcileHPA(ctx, craneAutoscaler, hpa, true); err != nil {
+		logger.Error(err, "Failed to reconcile HPA")
+		return r.handleOwnershipConflict(ctx, craneAutoscaler, err)
+	}
+
+	setNoConflictCondition(craneAutoscaler)
+	meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeAvailableCraneAutoscaler, Status: metav1.ConditionTrue, Reason: "Reconciling", Message: "Reconciliation successful"})
+	craneAutoscaler.Status.ObservedGeneration = craneAutoscaler.Generation
+	return ctrl.Result{RequeueAfter: r.nextEvaluation(craneAutoscaler, 0)}, nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	hpav2 "k8s.io/api/autoscaling/v2"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// DecisionStrategy decides which autoscaler is active in Auto mode. It is selected through spec.behavior.strategy.
+// The controller applies stabilization windows and the handoff to VPA on top of the decision, and only consults
+// the strategy once both autoscalers exist and the VPA has a recommendation.
+type DecisionStrategy interface {
+	Decide(ctx context.Context, input DecisionInput) (Decision, error)
+}
+
+// DecisionInput is the state a DecisionStrategy decides on. It must not be modified.
+type DecisionInput struct {
+	CranePodAutoscaler *autoscalingv1alpha1.CranePodAutoscaler
+	HPA                *hpav2.HorizontalPodAutoscaler
+	VPA                *vpav1.VerticalPodAutoscaler
+	Target             *TargetState
+	// Active is the currently active autoscaler, "HPA" or "VPA".
+	Active string
+	// Utilizations are the utilizations of the container resources recommended by the VPA
+	// together with their thresholds from spec.behavior.
+	Utilizations []ResourceUtilization
+}
+
+// Decision is the autoscaler a DecisionStrategy selected.
+type Decision struct {
+	// Autoscaler is the autoscaler to activate, "HPA" or "VPA".
+	Autoscaler string
+	// Reason explains a switch away from the active autoscaler and is recorded in the transition history.
+	Reason string
+	// Trigger is the container resource utilization the decision is based on, if any.
+	Trigger *ResourceUtilization
+}
+
+// builtinStrategies are the decision strategies that are always available.
+var builtinStrategies = map[autoscalingv1alpha1.DecisionStrategyName]DecisionStrategy{
+	autoscalingv1alpha1.DecisionStrategyThreshold: thresholdStrategy{},
+	autoscalingv1alpha1.DecisionStrategyRules:     rulesStrategy{},
+}
+
+// decisionStrategy returns the decision strategy selected by the cranepodautoscaler. Strategies registered
+// with the reconciler take precedence over the built-in ones of the same name.
+func (r *CranePodAutoscalerReconciler) decisionStrategy(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) (DecisionStrategy, error) {
+	name := craneAutoscaler.Spec.Behavior.Strategy
+	if name == "" {
+		name = autoscalingv1alpha1.DecisionStrategyThreshold
+	}
+	if strategy, ok := r.Strategies[name]; ok {
+		return strategy, nil
+	}
+	if strategy, ok := builtinStrategies[name]; ok {
+		return strategy, nil
+	}
+	return nil, fmt.Errorf("unknown decision strategy %q", name)
+}
+
+// thresholdStrategy switches to HPA once a utilization of the VPA recommendation exceeds its VPA to HPA threshold.
+// It switches back to VPA once the HPA is at min replicas and all utilizations are at or below their HPA to VPA thresholds.
+type thresholdStrategy struct{}
+
+func (thresholdStrategy) Decide(ctx context.Context, input DecisionInput) (Decision, error) {
+	logger := log.FromContext(ctx)
+	if len(input.Utilizations) == 0 {
+		// spec.behavior.containers selects no recommended container, as reported by the ContainersSelected condition.
+		// Without any utilization neither switch can be justified, so the active autoscaler is kept.
+		logger.Info("No container resource utilization to decide on, keeping the active autoscaler", "active", input.Active)
+		return Decision{Autoscaler: input.Active}, nil
+	}
+	if input.Active == refVPA {
+		// If the current scaling mode is VPA we need to check if any container resource has reached its utilization threshold.
+		// If yes, then we will switch to HPA.
+		trigger := mostCriticalUtilization(input.Utilizations, vpaToHPAThreshold)
+		if trigger != nil && trigger.Utilization > trigger.VPAToHPAThreshold {
+			logger.Info("VPA target capacity threshold reached.", "threshold", trigger.VPAToHPAThreshold,
+				"container", trigger.Container, "resource", trigger.Resource)
+			return Decision{Autoscaler: refHPA, Trigger: trigger,
+				Reason: fmt.Sprintf("VPA target capacity threshold of %.0f%% exceeded by %s", trigger.VPAToHPAThreshold*100, trigger)}, nil
+		}
+		return Decision{Autoscaler: refVPA, Trigger: trigger}, nil
+	}
+
+	// If the current scaling mode is HPA we need to check two things:
+	//   1. Is the HPA at minimum replicas?
+	//   2. Are all VPA recommendations at or below their (lower) HPA to VPA thresholds?
+	// If the answer is "yes" for both we will switch to VPA.
+	trigger := mostCriticalUtilization(input.Utilizations, hpaToVPAThreshold)
+	hpaMinReplicas := ptr.Deref(input.HPA.Spec.MinReplicas, 1)
+	hpaAtMinReplicas := input.HPA.Status.DesiredReplicas <= hpaMinReplicas
+	if hpaAtMinReplicas && (trigger == nil || trigger.Utilization <= trigger.HPAToVPAThreshold) {
+		logger.Info("HPA replicas at minimum and VPA is willing to scale down.", "hpaMinReplicas", hpaMinReplicas)
+		reason := fmt.Sprintf("HPA at minimum of %d replicas and all VPA utilizations at or below their thresholds", hpaMinReplicas)
+		if trigger != nil {
+			reason = fmt.Sprintf("%s, highest is %s (threshold %.0f%%)", reason, trigger, trigger.HPAToVPAThreshold*100)
+		}
+		return Decision{Autoscaler: refVPA, Trigger: trigger, Reason: reason}, nil
+	}
+	return Decision{Autoscaler: refHPA, Trigger: trigger}, nil
+}
+
+// rulesStrategy switches once one of the CEL expressions in spec.behavior.rules that switch away from the active
+// autoscaler evaluates to true.
+type rulesStrategy struct{}
+
+func (rulesStrategy) Decide(ctx context.Context, input DecisionInput) (Decision, error) {
+	variables := ruleVariables(input)
+	for i, rule := range input.CranePodAutoscaler.Spec.Behavior.Rules {
+		if rule.SwitchTo == input.Active {
+			continue
+		}
+		program, err := autoscalingv1alpha1.CompileRule(rule.Expression)
+		if err != nil {
+			return Decision{}, fmt.Errorf("spec.behavior.rules[%d]: %w", i, err)
+		}
+		matched, err := autoscalingv1alpha1.EvaluateRule(program, variables)
+		if err != nil {
+			return Decision{}, fmt.Errorf("spec.behavior.rules[%d]: %w", i, err)
+		}
+		if matched {
+			log.FromContext(ctx).Info("Switching rule matched", "rule", i, "expression", rule.Expression)
+			return Decision{Autoscaler: rule.SwitchTo, Reason: fmt.Sprintf("Rule %q matched", rule.Expression)}, nil
+		}
+	}
+	return Decision{Autoscaler: input.Active}, nil
+}
+
+// ruleVariables returns the variables the switching rules are evaluated with.
+func ruleVariables(input DecisionInput) *autoscalingv1alpha1.RuleVariables {
+	variables := &autoscalingv1alpha1.RuleVariables{
+		HPA: autoscalingv1alpha1.RuleHPA{
+			CurrentReplicas: int64(input.HPA.Status.CurrentReplicas),
+			DesiredReplicas: int64(input.HPA.Status.DesiredReplicas),
+			MinReplicas:     int64(ptr.Deref(input.HPA.Spec.MinReplicas, 1)),
+			MaxReplicas:     int64(input.HPA.Spec.MaxReplicas),
+		},
+	}
+	if input.Target != nil {
+		variables.Target = autoscalingv1alpha1.RuleTarget{Replicas: int64(input.Target.Replicas), CurrentReplicas: int64(input.Target.CurrentReplicas)}
+	}
+	for _, utilization := range input.Utilizations {
+		variables.VPA.Utilizations = append(variables.VPA.Utilizations, autoscalingv1alpha1.RuleUtilization{
+			Container: utilization.Container, Resource: string(utilization.Resource), Utilization: float64(utilization.Utilization),
+		})
+	}
+	return variables
+}
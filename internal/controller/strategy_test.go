@@ -0,0 +1,154 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	hpav2 "k8s.io/api/autoscaling/v2"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/utils/ptr"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// alwaysStrategy is a decision strategy that always selects the same autoscaler.
+type alwaysStrategy string
+
+func (s alwaysStrategy) Decide(context.Context, DecisionInput) (Decision, error) {
+	return Decision{Autoscaler: string(s), Reason: "always " + string(s)}, nil
+}
+
+var _ = Describe("Decision strategies", func() {
+	ctx := context.Background()
+
+	// decisionInput returns the input of a decision strategy for an HPA with min replicas of 2
+	// and a VPA recommendation with the given CPU target, whose upper bound is 1000m.
+	decisionInput := func(active string, hpaDesiredReplicas int32, targetCPU string) DecisionInput {
+		cpa := newCranePodAutoscaler("test-strategy")
+		recommendations := []vpav1.RecommendedContainerResources{vpaContainerRecommendation(targetCPU, "500Mi")}
+		return DecisionInput{
+			CranePodAutoscaler: cpa,
+			HPA: &hpav2.HorizontalPodAutoscaler{
+				Spec:   hpav2.HorizontalPodAutoscalerSpec{MinReplicas: ptr.To[int32](2), MaxReplicas: 10},
+				Status: hpav2.HorizontalPodAutoscalerStatus{DesiredReplicas: hpaDesiredReplicas},
+			},
+			VPA:          &vpav1.VerticalPodAutoscaler{},
+			Target:       &TargetState{Replicas: hpaDesiredReplicas, CurrentReplicas: hpaDesiredReplicas},
+			Active:       active,
+			Utilizations: getContainerResourceUtilizations(&cpa.Spec.Behavior, recommendations),
+		}
+	}
+
+	It("switches to HPA once a utilization exceeds its threshold", func() {
+		decision, err := thresholdStrategy{}.Decide(ctx, decisionInput(refVPA, 2, "900m"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Autoscaler).To(Equal(refHPA))
+		Expect(decision.Reason).To(Equal("VPA target capacity threshold of 80% exceeded by cpu of container app at 90%"))
+		Expect(decision.Trigger.Resource).To(BeEquivalentTo("cpu"))
+
+		decision, err = thresholdStrategy{}.Decide(ctx, decisionInput(refVPA, 2, "500m"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Autoscaler).To(Equal(refVPA))
+		Expect(decision.Reason).To(BeEmpty())
+	})
+
+	It("keeps the active autoscaler without any utilization", func() {
+		for _, active := range []string{refHPA, refVPA} {
+			input := decisionInput(active, 2, "500m")
+			input.Utilizations = nil
+			decision, err := thresholdStrategy{}.Decide(ctx, input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decision.Autoscaler).To(Equal(active))
+			Expect(decision.Reason).To(BeEmpty())
+		}
+	})
+
+	It("switches to VPA once the HPA is at min replicas and all utilizations are below their thresholds", func() {
+		decision, err := thresholdStrategy{}.Decide(ctx, decisionInput(refHPA, 2, "500m"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Autoscaler).To(Equal(refVPA))
+		Expect(decision.Reason).To(HavePrefix("HPA at minimum of 2 replicas"))
+
+		decision, err = thresholdStrategy{}.Decide(ctx, decisionInput(refHPA, 3, "500m"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Autoscaler).To(Equal(refHPA))
+
+		decision, err = thresholdStrategy{}.Decide(ctx, decisionInput(refHPA, 2, "900m"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Autoscaler).To(Equal(refHPA))
+	})
+
+	It("switches once a rule switching away from the active autoscaler matches", func() {
+		input := decisionInput(refVPA, 2, "500m")
+		input.CranePodAutoscaler.Spec.Behavior.Strategy = autoscalingv1alpha1.DecisionStrategyRules
+		input.CranePodAutoscaler.Spec.Behavior.Rules = []autoscalingv1alpha1.SwitchingRule{
+			{SwitchTo: refVPA, Expression: "true"},
+			{SwitchTo: refHPA, Expression: "vpa.maxUtilization('cpu') > 0.9 || hpa.desiredReplicas > 8"},
+		}
+		decision, err := rulesStrategy{}.Decide(ctx, input)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Autoscaler).To(Equal(refVPA))
+
+		input.HPA.Status.DesiredReplicas = 9
+		decision, err = rulesStrategy{}.Decide(ctx, input)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Autoscaler).To(Equal(refHPA))
+		Expect(decision.Reason).To(Equal(`Rule "vpa.maxUtilization('cpu') > 0.9 || hpa.desiredReplicas > 8" matched`))
+
+		input = decisionInput(refVPA, 2, "950m")
+		input.CranePodAutoscaler.Spec.Behavior.Rules = []autoscalingv1alpha1.SwitchingRule{
+			{SwitchTo: refHPA, Expression: "vpa.maxUtilization('cpu') > 0.9 && target.replicas == 2"},
+		}
+		decision, err = rulesStrategy{}.Decide(ctx, input)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Autoscaler).To(Equal(refHPA))
+	})
+
+	It("compiles each rule expression only once", func() {
+		const expression = "hpa.desiredReplicas > 4 && vpa.maxUtilization('memory') < 0.5"
+		program, err := autoscalingv1alpha1.CompileRule(expression)
+		Expect(err).NotTo(HaveOccurred())
+		cached, err := autoscalingv1alpha1.CompileRule(expression)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cached).To(BeIdenticalTo(program))
+
+		_, err = autoscalingv1alpha1.CompileRule("hpa.desiredReplicas")
+		Expect(err).To(MatchError(ContainSubstring("must return a bool")))
+	})
+
+	It("selects the strategy named in the behavior", func() {
+		r := &CranePodAutoscalerReconciler{Strategies: map[autoscalingv1alpha1.DecisionStrategyName]DecisionStrategy{
+			"AlwaysVPA": alwaysStrategy(refVPA),
+		}}
+		cpa := newCranePodAutoscaler("test-strategy")
+		strategy, err := r.decisionStrategy(cpa)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strategy).To(Equal(thresholdStrategy{}))
+
+		cpa.Spec.Behavior.Strategy = "AlwaysVPA"
+		strategy, err = r.decisionStrategy(cpa)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strategy).To(Equal(alwaysStrategy(refVPA)))
+
+		cpa.Spec.Behavior.Strategy = "Unknown"
+		_, err = r.decisionStrategy(cpa)
+		Expect(err).To(MatchError(`unknown decision strategy "Unknown"`))
+	})
+})
@@ -0,0 +1,120 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// Reasons of the TargetResolved condition
+const (
+	targetReasonResolved        = "Resolved"
+	targetReasonInvalidRef      = "InvalidTargetRef"
+	targetReasonUnknownKind     = "UnknownKind"
+	targetReasonNotFound        = "TargetNotFound"
+	targetReasonResolutionError = "ResolutionFailed"
+)
+
+// targetResolutionError describes why the scale target of a cranepodautoscaler could not be resolved.
+type targetResolutionError struct {
+	reason  string
+	message string
+}
+
+func (e *targetResolutionError) Error() string {
+	return e.message
+}
+
+// TargetState is the state of the workload targeted by a cranepodautoscaler as reported by its /scale subresource.
+type TargetState struct {
+	// Replicas is the desired number of replicas of the target.
+	Replicas int32
+	// CurrentReplicas is the number of replicas the target currently runs.
+	CurrentReplicas int32
+	// Selector is the label selector of the pods of the target.
+	Selector string
+}
+
+// resolveTarget checks that the workload targeted by the cranepodautoscaler exists and can be scaled
+// by fetching its /scale subresource, and returns its state. Every workload with a scale subresource is supported,
+// e.g. Deployments, StatefulSets, ReplicaSets and custom resources such as Argo Rollouts.
+// A *targetResolutionError is returned if the target does not exist or cannot be scaled.
+func (r *CranePodAutoscalerReconciler) resolveTarget(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) (*TargetState, error) {
+	_, scale, err := r.getTargetScale(ctx, craneAutoscaler)
+	if err != nil {
+		return nil, err
+	}
+	replicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	currentReplicas, _, _ := unstructured.NestedInt64(scale.Object, "status", "replicas")
+	selector, _, _ := unstructured.NestedString(scale.Object, "status", "selector")
+	return &TargetState{Replicas: int32(replicas), CurrentReplicas: int32(currentReplicas), Selector: selector}, nil
+}
+
+// scaleTarget sets the replicas of the workload targeted by the cranepodautoscaler through its /scale subresource.
+func (r *CranePodAutoscalerReconciler) scaleTarget(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, replicas int32) error {
+	target, scale, err := r.getTargetScale(ctx, craneAutoscaler)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(scale.Object, int64(replicas), "spec", "replicas"); err != nil {
+		return err
+	}
+	return r.SubResource("scale").Update(ctx, target, client.WithSubResourceBody(scale))
+}
+
+// getTargetScale returns the workload targeted by the cranepodautoscaler together with its /scale subresource.
+func (r *CranePodAutoscalerReconciler) getTargetScale(ctx context.Context, craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	ref := craneAutoscaler.Spec.HPA.ScaleTargetRef
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, nil, &targetResolutionError{reason: targetReasonInvalidRef,
+			message: fmt.Sprintf("Invalid apiVersion %q of target %s/%s: %s", ref.APIVersion, ref.Kind, ref.Name, err)}
+	}
+	gvk := gv.WithKind(ref.Kind)
+	if _, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil, &targetResolutionError{reason: targetReasonUnknownKind,
+				message: fmt.Sprintf("Kind %s of target %s is not served by the cluster", gvk, ref.Name)}
+		}
+		return nil, nil, err
+	}
+
+	target := &unstructured.Unstructured{}
+	target.SetGroupVersionKind(gvk)
+	target.SetNamespace(craneAutoscaler.Namespace)
+	target.SetName(ref.Name)
+	scale := &unstructured.Unstructured{}
+	scale.SetGroupVersionKind(autoscalingv1.SchemeGroupVersion.WithKind("Scale"))
+	if err := r.SubResource("scale").Get(ctx, target, scale); err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+			return nil, nil, &targetResolutionError{reason: targetReasonNotFound,
+				message: fmt.Sprintf("Target %s %s does not exist or has no scale subresource", gvk.Kind, ref.Name)}
+		}
+		return nil, nil, err
+	}
+	return target, scale, nil
+}
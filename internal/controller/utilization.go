@@ -0,0 +1,189 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+// typeContainersSelectedCraneAutoscaler reports whether the container selection yields utilizations to decide on.
+const typeContainersSelectedCraneAutoscaler = "ContainersSelected"
+
+// Reasons of the ContainersSelected condition and its event
+const (
+	containersReasonSelected  = "ContainersSelected"
+	containersReasonNoneMatch = "NoContainersSelected"
+	eventReasonNoneSelected   = "NoContainersSelected"
+)
+
+// ResourceUtilization is the ratio between the VPA target and the VPA upper bound
+// of one resource of one container, together with the thresholds that apply to it.
+type ResourceUtilization struct {
+	// Container is the name of the container, empty for the weighted average of all selected containers.
+	Container string
+	Resource  corev1.ResourceName
+	// Utilization is the ratio, e.g. 0.9 for 90%.
+	Utilization float32
+	// VPAToHPAThreshold and HPAToVPAThreshold are the thresholds from spec.behavior as ratios.
+	VPAToHPAThreshold float32
+	HPAToVPAThreshold float32
+}
+
+// String describes the utilization for the status, events and logs.
+func (u *ResourceUtilization) String() string {
+	if u.Container == "" {
+		return fmt.Sprintf("%s of all containers (weighted average) at %.0f%%", u.Resource, u.Utilization*100)
+	}
+	return fmt.Sprintf("%s of container %s at %.0f%%", u.Resource, u.Container, u.Utilization*100)
+}
+
+// switchingResources are the resources considered for the switching decision.
+var switchingResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// getContainerResourceUtilizations returns the utilizations of the containers selected in the behavior.
+// With aggregation WeightedAverage there is one utilization per resource that averages all selected containers.
+func getContainerResourceUtilizations(behavior *autoscalingv1alpha1.CranePodAutoscalerBehavior, vpaContainerResources []vpav1.RecommendedContainerResources) []ResourceUtilization {
+	utilizations := make([]ResourceUtilization, 0, len(vpaContainerResources)*len(switchingResources))
+	for _, containerResource := range vpaContainerResources {
+		if !behavior.Containers.Selects(containerResource.ContainerName) {
+			continue
+		}
+		for _, resourceName := range switchingResources {
+			var utilization float32
+			upperBound := quantityValue(resourceName, containerResource.UpperBound)
+			if upperBound > 0 {
+				utilization = float32(quantityValue(resourceName, containerResource.Target)) / float32(upperBound)
+			}
+			utilizations = append(utilizations, newResourceUtilization(behavior, containerResource.ContainerName, resourceName, utilization))
+		}
+	}
+
+	if behavior.Containers.AggregationOrDefault() == autoscalingv1alpha1.ContainerAggregationWeightedAverage {
+		return weightedAverageUtilizations(behavior, utilizations)
+	}
+	return utilizations
+}
+
+func newResourceUtilization(behavior *autoscalingv1alpha1.CranePodAutoscalerBehavior, containerName string, resourceName corev1.ResourceName, utilization float32) ResourceUtilization {
+	vpaToHPA, hpaToVPA := behavior.ThresholdPercentsFor(containerName, resourceName)
+	return ResourceUtilization{
+		Container:         containerName,
+		Resource:          resourceName,
+		Utilization:       utilization,
+		VPAToHPAThreshold: float32(vpaToHPA) / float32(100),
+		HPAToVPAThreshold: float32(hpaToVPA) / float32(100),
+	}
+}
+
+// weightedAverageUtilizations averages the container utilizations per resource.
+// The averages are compared against the thresholds that apply to all containers.
+func weightedAverageUtilizations(behavior *autoscalingv1alpha1.CranePodAutoscalerBehavior, utilizations []ResourceUtilization) []ResourceUtilization {
+	if len(utilizations) == 0 {
+		return utilizations
+	}
+	averages := make([]ResourceUtilization, 0, len(switchingResources))
+	for _, resourceName := range switchingResources {
+		var weightedSum, totalWeight float32
+		for _, u := range utilizations {
+			if u.Resource != resourceName {
+				continue
+			}
+			weight := float32(behavior.Containers.WeightOf(u.Container))
+			weightedSum += u.Utilization * weight
+			totalWeight += weight
+		}
+		// Without any weight there is nothing to average. No utilization is reported rather than one of zero,
+		// which would pass for a workload far below every threshold.
+		if totalWeight == 0 {
+			continue
+		}
+		averages = append(averages, newResourceUtilization(behavior, "", resourceName, weightedSum/totalWeight))
+	}
+	return averages
+}
+
+// updateContainerSelectionCondition records whether the container selection yields any utilization for the
+// recommended containers. Without one the Threshold strategy keeps the active autoscaler,
+// so a warning event is emitted once the selection stops matching.
+func (r *CranePodAutoscalerReconciler) updateContainerSelectionCondition(craneAutoscaler *autoscalingv1alpha1.CranePodAutoscaler, vpaContainerResources []vpav1.RecommendedContainerResources, utilizations []ResourceUtilization) {
+	if len(utilizations) > 0 {
+		meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeContainersSelectedCraneAutoscaler,
+			Status: metav1.ConditionTrue, Reason: containersReasonSelected, Message: "The container selection matches recommended containers"})
+		return
+	}
+	containers := make([]string, 0, len(vpaContainerResources))
+	for _, containerResource := range vpaContainerResources {
+		containers = append(containers, containerResource.ContainerName)
+	}
+	message := fmt.Sprintf("spec.behavior.containers selects none of the recommended containers %v with a positive weight, the Threshold strategy keeps the active autoscaler", containers)
+	if meta.SetStatusCondition(&craneAutoscaler.Status.Conditions, metav1.Condition{Type: typeContainersSelectedCraneAutoscaler,
+		Status: metav1.ConditionFalse, Reason: containersReasonNoneMatch, Message: message}) {
+		r.event(craneAutoscaler, corev1.EventTypeWarning, eventReasonNoneSelected, "%s", message)
+	}
+}
+
+// quantityValue returns CPU quantities in millicores and all other quantities in their base unit.
+func quantityValue(resourceName corev1.ResourceName, resources corev1.ResourceList) int64 {
+	quantity, ok := resources[resourceName]
+	if !ok {
+		return 0
+	}
+	if resourceName == corev1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
+
+// mostCriticalUtilization returns the utilization that is closest to or furthest above its threshold.
+// It returns nil if there are no utilizations.
+func mostCriticalUtilization(utilizations []ResourceUtilization, threshold func(*ResourceUtilization) float32) *ResourceUtilization {
+	var critical *ResourceUtilization
+	for i := range utilizations {
+		u := &utilizations[i]
+		if critical == nil || u.Utilization-threshold(u) > critical.Utilization-threshold(critical) {
+			critical = u
+		}
+	}
+	return critical
+}
+
+func vpaToHPAThreshold(u *ResourceUtilization) float32 { return u.VPAToHPAThreshold }
+
+func hpaToVPAThreshold(u *ResourceUtilization) float32 { return u.HPAToVPAThreshold }
+
+// summarizeVPATarget returns a compact summary of the VPA target, e.g. "app: cpu=500m memory=512Mi".
+func summarizeVPATarget(vpaContainerResources []vpav1.RecommendedContainerResources) string {
+	containers := make([]string, 0, len(vpaContainerResources))
+	for _, containerResource := range vpaContainerResources {
+		resources := make([]string, 0, len(switchingResources))
+		for _, resourceName := range switchingResources {
+			if quantity, ok := containerResource.Target[resourceName]; ok {
+				resources = append(resources, fmt.Sprintf("%s=%s", resourceName, quantity.String()))
+			}
+		}
+		containers = append(containers, fmt.Sprintf("%s: %s", containerResource.ContainerName, strings.Join(resources, " ")))
+	}
+	return strings.Join(containers, ", ")
+}
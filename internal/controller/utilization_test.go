@@ -0,0 +1,162 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+
+	autoscalingv1alpha1 "github.com/phihos/crane-autoscaler/api/v1alpha1"
+)
+
+func namedContainerRecommendation(name, targetCPU, targetMem string) vpav1.RecommendedContainerResources {
+	recommendation := vpaContainerRecommendation(targetCPU, targetMem)
+	recommendation.ContainerName = name
+	return recommendation
+}
+
+var _ = Describe("Container resource utilization", func() {
+	behavior := &autoscalingv1alpha1.CranePodAutoscalerBehavior{
+		VPAToHPAThresholdPercent: 80,
+		HPAToVPAThresholdPercent: 60,
+		ResourceThresholds: []autoscalingv1alpha1.ResourceThreshold{
+			{Resource: corev1.ResourceMemory, VPAToHPAThresholdPercent: 95},
+			{Resource: corev1.ResourceCPU, VPAToHPAThresholdPercent: 70, HPAToVPAThresholdPercent: 50},
+			{Resource: corev1.ResourceCPU, ContainerName: "sidecar", VPAToHPAThresholdPercent: 90},
+		},
+	}
+
+	It("applies global, per-resource and per-container thresholds", func() {
+		utilizations := getContainerResourceUtilizations(behavior, []vpav1.RecommendedContainerResources{
+			namedContainerRecommendation("app", "500m", "500Mi"),
+			namedContainerRecommendation("sidecar", "500m", "500Mi"),
+		})
+		Expect(utilizations).To(HaveLen(4))
+
+		Expect(utilizations[0].Container).To(Equal("app"))
+		Expect(utilizations[0].Resource).To(Equal(corev1.ResourceCPU))
+		Expect(utilizations[0].VPAToHPAThreshold).To(BeNumerically("~", 0.7, 1e-6))
+		Expect(utilizations[0].HPAToVPAThreshold).To(BeNumerically("~", 0.5, 1e-6))
+
+		Expect(utilizations[1].Resource).To(Equal(corev1.ResourceMemory))
+		Expect(utilizations[1].VPAToHPAThreshold).To(BeNumerically("~", 0.95, 1e-6))
+		Expect(utilizations[1].HPAToVPAThreshold).To(BeNumerically("~", 0.6, 1e-6))
+
+		Expect(utilizations[2].Container).To(Equal("sidecar"))
+		Expect(utilizations[2].VPAToHPAThreshold).To(BeNumerically("~", 0.9, 1e-6))
+		Expect(utilizations[2].HPAToVPAThreshold).To(BeNumerically("~", 0.6, 1e-6))
+	})
+
+	It("reports the container resource furthest above its threshold", func() {
+		utilizations := getContainerResourceUtilizations(behavior, []vpav1.RecommendedContainerResources{
+			namedContainerRecommendation("app", "750m", "920Mi"),
+			namedContainerRecommendation("sidecar", "850m", "100Mi"),
+		})
+
+		trigger := mostCriticalUtilization(utilizations, vpaToHPAThreshold)
+		Expect(trigger).NotTo(BeNil())
+		Expect(trigger.Container).To(Equal("app"))
+		Expect(trigger.Resource).To(Equal(corev1.ResourceCPU))
+		Expect(trigger.Utilization).To(BeNumerically(">", trigger.VPAToHPAThreshold))
+	})
+
+	It("returns no trigger without recommendations", func() {
+		Expect(mostCriticalUtilization(getContainerResourceUtilizations(behavior, nil), vpaToHPAThreshold)).To(BeNil())
+	})
+
+	Context("container selection", func() {
+		recommendations := []vpav1.RecommendedContainerResources{
+			namedContainerRecommendation("app", "600m", "400Mi"),
+			namedContainerRecommendation("istio-proxy", "900m", "900Mi"),
+			namedContainerRecommendation("log-shipper", "300m", "200Mi"),
+		}
+
+		It("ignores excluded containers", func() {
+			b := behavior.DeepCopy()
+			b.Containers = &autoscalingv1alpha1.ContainerSelection{Exclude: []string{"istio-*"}}
+
+			utilizations := getContainerResourceUtilizations(b, recommendations)
+			Expect(utilizations).To(HaveLen(4))
+			for _, u := range utilizations {
+				Expect(u.Container).NotTo(Equal("istio-proxy"))
+			}
+		})
+
+		It("only considers included containers", func() {
+			b := behavior.DeepCopy()
+			b.Containers = &autoscalingv1alpha1.ContainerSelection{Include: []string{"app", "log-*"}, Exclude: []string{"log-*"}}
+
+			utilizations := getContainerResourceUtilizations(b, recommendations)
+			Expect(utilizations).To(HaveLen(2))
+			Expect(utilizations[0].Container).To(Equal("app"))
+		})
+
+		It("only considers the main container", func() {
+			b := behavior.DeepCopy()
+			b.Containers = &autoscalingv1alpha1.ContainerSelection{
+				Aggregation:   autoscalingv1alpha1.ContainerAggregationMainContainer,
+				MainContainer: "log-shipper",
+			}
+
+			utilizations := getContainerResourceUtilizations(b, recommendations)
+			Expect(utilizations).To(HaveLen(2))
+			Expect(utilizations[0].Container).To(Equal("log-shipper"))
+		})
+
+		It("averages utilizations per resource by weight", func() {
+			b := behavior.DeepCopy()
+			b.Containers = &autoscalingv1alpha1.ContainerSelection{
+				Aggregation: autoscalingv1alpha1.ContainerAggregationWeightedAverage,
+				Weights: []autoscalingv1alpha1.ContainerWeight{
+					{ContainerName: "app", Weight: 3},
+					{ContainerName: "log-shipper", Weight: 0},
+				},
+			}
+
+			utilizations := getContainerResourceUtilizations(b, recommendations)
+			Expect(utilizations).To(HaveLen(2))
+			Expect(utilizations[0].Container).To(BeEmpty())
+			Expect(utilizations[0].Resource).To(Equal(corev1.ResourceCPU))
+			Expect(utilizations[0].Utilization).To(BeNumerically("~", 0.675, 1e-6))
+			Expect(utilizations[0].VPAToHPAThreshold).To(BeNumerically("~", 0.7, 1e-6))
+			Expect(utilizations[1].Resource).To(Equal(corev1.ResourceMemory))
+			Expect(utilizations[1].Utilization).To(BeNumerically("~", 0.525, 1e-6))
+		})
+	})
+
+	It("reports no utilization if the selected containers weigh nothing", func() {
+		b := behavior.DeepCopy()
+		b.Containers = &autoscalingv1alpha1.ContainerSelection{
+			Aggregation: autoscalingv1alpha1.ContainerAggregationWeightedAverage,
+			Include:     []string{"app"},
+			Weights:     []autoscalingv1alpha1.ContainerWeight{{ContainerName: "app", Weight: 0}, {ContainerName: "sidecar", Weight: 1}},
+		}
+
+		Expect(getContainerResourceUtilizations(b, []vpav1.RecommendedContainerResources{
+			namedContainerRecommendation("app", "500m", "500Mi"),
+		})).To(BeEmpty())
+	})
+
+	It("summarizes the VPA target per container", func() {
+		Expect(summarizeVPATarget([]vpav1.RecommendedContainerResources{
+			namedContainerRecommendation("app", "500m", "512Mi"),
+			namedContainerRecommendation("sidecar", "100m", "64Mi"),
+		})).To(Equal("app: cpu=500m memory=512Mi, sidecar: cpu=100m memory=64Mi"))
+	})
+})